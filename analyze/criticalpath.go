@@ -0,0 +1,159 @@
+// Package analyze derives timing insights, such as the critical path, from spans recorded locally.
+package analyze
+
+import (
+	"github.com/nextrevision/traci/spool"
+	"sort"
+	"time"
+)
+
+// Step is the critical path analysis of a single span.
+type Step struct {
+	Record spool.Record
+	// Depth is the nesting level of the span, where spans without a recorded parent are at depth 0.
+	Depth int
+	// Critical is set for spans on the chain of spans determining the total wall-clock time.
+	Critical bool
+	// Slack is how much longer the span could have taken without delaying the critical path of its parent.
+	Slack time.Duration
+	// Wait is the time between the start of the parent, or of the whole trace for top level spans, and the start of
+	// the span.
+	Wait time.Duration
+	// WaitedOn is the name of the latest sibling which finished before the span started, which the span is assumed to
+	// have depended on. It is empty for spans which were running as soon as their parent started.
+	WaitedOn string
+}
+
+// node is a span along with its children, ordered by start time.
+type node struct {
+	record   spool.Record
+	start    time.Time
+	end      time.Time
+	depth    int
+	children []*node
+	step     Step
+}
+
+// CriticalPath computes the critical path of records and returns a step for every span in tree order: each span is
+// followed by its children, ordered by start time.
+//
+// Traces do not record dependencies between spans, so the path is found by walking backwards from the end of each span
+// and following the child which finished last before that point, repeated from that child's start. Spans without a
+// recorded parent are treated as children of the whole trace.
+func CriticalPath(records []spool.Record) []Step {
+	if len(records) == 0 {
+		return nil
+	}
+
+	nodes := make(map[string]*node, len(records))
+	ordered := make([]*node, 0, len(records))
+	for _, r := range records {
+		n := &node{record: r, start: r.StartTime(), end: r.EndTime()}
+		nodes[r.SpanID] = n
+		ordered = append(ordered, n)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].start.Before(ordered[j].start)
+	})
+
+	root := &node{start: ordered[0].start, end: ordered[0].end, depth: -1}
+	for _, n := range ordered {
+		parent, ok := nodes[n.record.ParentSpanID]
+		if !ok || parent == n {
+			parent = root
+		}
+		parent.children = append(parent.children, n)
+		if n.end.After(root.end) {
+			root.end = n.end
+		}
+	}
+
+	markCritical(root, root.end)
+	analyze(root)
+
+	steps := make([]Step, 0, len(records))
+	var flatten func(n *node)
+	flatten = func(n *node) {
+		for _, child := range n.children {
+			steps = append(steps, child.step)
+			flatten(child)
+		}
+	}
+	flatten(root)
+	return steps
+}
+
+// markCritical marks the children of n on the critical path ending at until.
+func markCritical(n *node, until time.Time) {
+	cursor := until
+	for cursor.After(n.start) {
+		// Follow the child which finished last before the cursor, falling back to a child still running at the cursor
+		var next *node
+		for _, child := range n.children {
+			if child.step.Critical || !child.start.Before(cursor) {
+				continue
+			}
+			if next == nil || precedes(next, child, cursor) {
+				next = child
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		next.step.Critical = true
+		markCritical(next, minTime(next.end, cursor))
+		cursor = next.start
+	}
+}
+
+// analyze computes the slack and wait time of the descendants of n.
+func analyze(n *node) {
+	for _, child := range n.children {
+		child.step.Record = child.record
+		child.step.Depth = n.depth + 1
+		child.depth = n.depth + 1
+		child.step.Wait = child.start.Sub(n.start)
+
+		var dependency *node
+		for _, sibling := range n.children {
+			if sibling != child && !sibling.end.After(child.start) && (dependency == nil || sibling.end.After(dependency.end)) {
+				dependency = sibling
+			}
+		}
+		if dependency != nil {
+			child.step.WaitedOn = dependency.record.Name
+		}
+
+		if !child.step.Critical {
+			// A span can run until the next critical sibling starts, or until its parent ends
+			deadline := n.end
+			for _, sibling := range n.children {
+				if sibling.step.Critical && !sibling.start.Before(child.end) && sibling.start.Before(deadline) {
+					deadline = sibling.start
+				}
+			}
+			if slack := deadline.Sub(child.end); slack > 0 {
+				child.step.Slack = slack
+			}
+		}
+
+		analyze(child)
+	}
+}
+
+// precedes reports whether b is a better candidate than a for the span running before cursor on the critical path.
+func precedes(a, b *node, cursor time.Time) bool {
+	aFinished, bFinished := !a.end.After(cursor), !b.end.After(cursor)
+	if aFinished != bFinished {
+		return bFinished
+	}
+	return b.end.After(a.end)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,92 @@
+package analyze
+
+import (
+	"github.com/nextrevision/traci/spool"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func record(name, parentSpanID string, start, end time.Duration) spool.Record {
+	base := time.Unix(1700000000, 0)
+	return spool.Record{Span: spool.Span{
+		Name:              name,
+		SpanID:            name,
+		ParentSpanID:      parentSpanID,
+		StartTimeUnixNano: spool.Uint64(base.Add(start).UnixNano()),
+		EndTimeUnixNano:   spool.Uint64(base.Add(end).UnixNano()),
+	}}
+}
+
+func TestCriticalPath(t *testing.T) {
+	type result struct {
+		name     string
+		depth    int
+		critical bool
+		slack    time.Duration
+		wait     time.Duration
+		waitedOn string
+	}
+
+	tests := []struct {
+		name    string
+		records []spool.Record
+		want    []result
+	}{
+		{
+			name:    "Case for no spans",
+			records: nil,
+			want:    []result{},
+		},
+		{
+			name: "Case for parallel jobs without a pipeline span",
+			records: []spool.Record{
+				record("lint", "", 0, 2*time.Minute),
+				record("build", "", 0, 5*time.Minute),
+				record("unit", "", 5*time.Minute, 7*time.Minute),
+				record("e2e", "", 6*time.Minute, 12*time.Minute),
+				record("e2e-setup", "e2e", 6*time.Minute, 8*time.Minute),
+				record("e2e-run", "e2e", 8*time.Minute, 11*time.Minute),
+			},
+			want: []result{
+				{name: "lint", critical: false, slack: 4 * time.Minute},
+				{name: "build", critical: true},
+				{name: "unit", critical: false, slack: 5 * time.Minute, wait: 5 * time.Minute, waitedOn: "build"},
+				{name: "e2e", critical: true, wait: 6 * time.Minute, waitedOn: "build"},
+				{name: "e2e-setup", depth: 1, critical: true},
+				{name: "e2e-run", depth: 1, critical: true, wait: 2 * time.Minute, waitedOn: "e2e-setup"},
+			},
+		},
+		{
+			name: "Case for sibling finishing before the next critical span",
+			records: []spool.Record{
+				record("pipeline", "", 0, 10*time.Minute),
+				record("build", "pipeline", 0, 4*time.Minute),
+				record("docs", "pipeline", 0, time.Minute),
+				record("deploy", "pipeline", 4*time.Minute, 10*time.Minute),
+			},
+			want: []result{
+				{name: "pipeline", critical: true},
+				{name: "build", depth: 1, critical: true},
+				{name: "docs", depth: 1, critical: false, slack: 3 * time.Minute},
+				{name: "deploy", depth: 1, critical: true, wait: 4 * time.Minute, waitedOn: "build"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []result{}
+			for _, step := range CriticalPath(tt.records) {
+				got = append(got, result{
+					name:     step.Record.Name,
+					depth:    step.Depth,
+					critical: step.Critical,
+					slack:    step.Slack,
+					wait:     step.Wait,
+					waitedOn: step.WaitedOn,
+				})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nextrevision/traci/analyze"
+	"github.com/nextrevision/traci/spool"
+	"github.com/nextrevision/traci/summary"
+	"github.com/spf13/cobra"
+	"strings"
+	"text/tabwriter"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "analyze spans recorded locally",
+}
+
+var analyzeCriticalPathCmd = &cobra.Command{
+	Use:   "critical-path [path...]",
+	Short: "find the chain of spans which determined the total wall-clock time",
+	Long: `find the chain of spans which determined the total wall-clock time of a trace and report the slack of every
+span, which is how much longer it could have taken without delaying the critical path. Spans which only started after a
+sibling finished are flagged as waiting on that dependency. Paths can be spool directories or OTLP JSON files and
+default to the configured spool directory.
+
+The table lists the top level spans, such as the jobs of a pipeline, and every span on the critical path, which is
+marked with a *.
+
+Examples:
+
+traci analyze critical-path
+
+traci analyze critical-path --trace-id 5b8efff798038103d269b633813fc60c collector-export.json`,
+	RunE: runAnalyzeCriticalPath,
+}
+
+func init() {
+	analyzeCriticalPathCmd.Flags().String("trace-id", "", "only analyze the spans of this trace")
+	analyzeCriticalPathCmd.Flags().Bool("json", false, "print the analysis of every span as JSON")
+
+	analyzeCmd.AddCommand(analyzeCriticalPathCmd)
+	rootCmd.AddCommand(analyzeCmd)
+}
+
+// criticalPathStep is the JSON representation of an analyze.Step.
+type criticalPathStep struct {
+	Name         string  `json:"name"`
+	TraceID      string  `json:"trace_id"`
+	SpanID       string  `json:"span_id"`
+	ParentSpanID string  `json:"parent_span_id,omitempty"`
+	Depth        int     `json:"depth"`
+	Critical     bool    `json:"critical"`
+	Start        float64 `json:"start_seconds"`
+	Duration     float64 `json:"duration_seconds"`
+	Slack        float64 `json:"slack_seconds"`
+	Wait         float64 `json:"wait_seconds"`
+	WaitedOn     string  `json:"waited_on,omitempty"`
+}
+
+func runAnalyzeCriticalPath(cmd *cobra.Command, args []string) error {
+	records, err := readSpool(args)
+	if err != nil {
+		return err
+	}
+
+	if traceID, _ := cmd.Flags().GetString("trace-id"); traceID != "" {
+		var filtered []spool.Record
+		for _, r := range records {
+			if strings.EqualFold(r.TraceID, traceID) {
+				filtered = append(filtered, r)
+			}
+		}
+		records = filtered
+	}
+	if len(records) == 0 {
+		return errors.New("no spans found to analyze")
+	}
+
+	steps := analyze.CriticalPath(records)
+	start, end := records[0].StartTime(), records[0].EndTime()
+	for _, r := range records {
+		if r.EndTime().After(end) {
+			end = r.EndTime()
+		}
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		output := make([]criticalPathStep, len(steps))
+		for i, step := range steps {
+			output[i] = criticalPathStep{
+				Name:         step.Record.Name,
+				TraceID:      step.Record.TraceID,
+				SpanID:       step.Record.SpanID,
+				ParentSpanID: step.Record.ParentSpanID,
+				Depth:        step.Depth,
+				Critical:     step.Critical,
+				Start:        step.Record.StartTime().Sub(start).Seconds(),
+				Duration:     step.Record.Duration().Seconds(),
+				Slack:        step.Slack.Seconds(),
+				Wait:         step.Wait.Seconds(),
+				WaitedOn:     step.WaitedOn,
+			}
+		}
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	}
+
+	critical := 0
+	for _, step := range steps {
+		if step.Critical {
+			critical++
+		}
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wall-clock time %s, %d of %d spans on the critical path\n\n", summary.FormatDuration(end.Sub(start)), critical, len(steps))
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tSTART\tDURATION\tSLACK\tWAIT\tSTATE\tSPAN")
+	for _, step := range steps {
+		if step.Depth > 0 && !step.Critical {
+			continue
+		}
+		marker := ""
+		if step.Critical {
+			marker = "*"
+		}
+		state := "running"
+		if step.WaitedOn != "" {
+			state = "waited on " + step.WaitedOn
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s%s\n",
+			marker,
+			summary.FormatDuration(step.Record.StartTime().Sub(start)),
+			summary.FormatDuration(step.Record.Duration()),
+			summary.FormatDuration(step.Slack),
+			summary.FormatDuration(step.Wait),
+			state,
+			strings.Repeat("  ", step.Depth),
+			step.Record.Name,
+		)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/nextrevision/traci/config"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Attributes of the budget.exceeded span event.
+const (
+	budgetLimitKey  = attribute.Key("traci.budget.limit")
+	budgetActionKey = attribute.Key("traci.budget.action")
+)
+
+// newBudget returns the duration budget and action for commandLine. A budget passed as a flag takes precedence over
+// the first matching budget rule, which takes precedence over a budget set through the environment or config file.
+func newBudget(cmd *cobra.Command, traciConfig *config.Config, commandLine string) (time.Duration, config.BudgetAction) {
+	action := traciConfig.BudgetAction
+	if action == "" {
+		action = config.BudgetActionWarn
+	}
+
+	if flag := cmd.Flags().Lookup("budget"); flag != nil && flag.Changed {
+		return traciConfig.Budget, action
+	}
+
+	for _, rule := range traciConfig.Budgets {
+		if matchCommand(rule.Command, commandLine) {
+			if rule.Action != "" {
+				action = rule.Action
+			}
+			return rule.Budget, action
+		}
+	}
+
+	return traciConfig.Budget, action
+}
+
+// matchCommand reports whether commandLine, or the name of its executable, matches pattern where * matches any text.
+func matchCommand(pattern, commandLine string) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false
+	}
+
+	executable, _, _ := strings.Cut(commandLine, " ")
+	if i := strings.LastIndex(executable, "/"); i >= 0 {
+		executable = executable[i+1:]
+	}
+	return re.MatchString(commandLine) || re.MatchString(executable)
+}
+
+// exceedBudget records the budget.exceeded event on span, returning the error describing the exceeded budget.
+func exceedBudget(span trace.Span, budget time.Duration, action config.BudgetAction, duration time.Duration) error {
+	span.AddEvent("budget.exceeded", trace.WithAttributes(
+		budgetLimitKey.Float64(budget.Seconds()),
+		budgetActionKey.String(string(action)),
+	))
+
+	return fmt.Errorf("command exceeded its budget of %s by %s", budget, (duration - budget).Round(time.Millisecond))
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"github.com/nextrevision/traci/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMatchCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		commandLine string
+		want        bool
+	}{
+		{name: "Case for exact command line", pattern: "make build", commandLine: "make build", want: true},
+		{name: "Case for wildcard across paths", pattern: "go test *", commandLine: "go test ./...", want: true},
+		{name: "Case for executable name", pattern: "make", commandLine: "/usr/bin/make build", want: true},
+		{name: "Case for regexp characters", pattern: "make (all)", commandLine: "make (all)", want: true},
+		{name: "Case for no match", pattern: "make test*", commandLine: "make build", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchCommand(tt.pattern, tt.commandLine))
+		})
+	}
+}
+
+func TestNewBudget(t *testing.T) {
+	rules := []config.BudgetRule{
+		{Command: "make test*", Budget: 10 * time.Minute, Action: config.BudgetActionFail},
+		{Command: "make *", Budget: 5 * time.Minute},
+	}
+
+	tests := []struct {
+		name        string
+		config      config.Config
+		flagArgs    []string
+		commandLine string
+		wantBudget  time.Duration
+		wantAction  config.BudgetAction
+	}{
+		{
+			name:        "Case for no budget",
+			config:      config.Config{},
+			commandLine: "make build",
+			wantBudget:  0,
+			wantAction:  config.BudgetActionWarn,
+		},
+		{
+			name:        "Case for first matching rule",
+			config:      config.Config{Budget: time.Minute, BudgetAction: config.BudgetActionWarn, Budgets: rules},
+			commandLine: "make test",
+			wantBudget:  10 * time.Minute,
+			wantAction:  config.BudgetActionFail,
+		},
+		{
+			name:        "Case for rule inheriting the action",
+			config:      config.Config{Budget: time.Minute, BudgetAction: config.BudgetActionFail, Budgets: rules},
+			commandLine: "make build",
+			wantBudget:  5 * time.Minute,
+			wantAction:  config.BudgetActionFail,
+		},
+		{
+			name:        "Case for default budget without a matching rule",
+			config:      config.Config{Budget: time.Minute, BudgetAction: config.BudgetActionWarn, Budgets: rules},
+			commandLine: "go build",
+			wantBudget:  time.Minute,
+			wantAction:  config.BudgetActionWarn,
+		},
+		{
+			name:        "Case for budget flag overriding rules",
+			config:      config.Config{Budget: time.Second, BudgetAction: config.BudgetActionWarn, Budgets: rules},
+			flagArgs:    []string{"--budget", "1s"},
+			commandLine: "make test",
+			wantBudget:  time.Second,
+			wantAction:  config.BudgetActionWarn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().Duration("budget", 0, "")
+			assert.Nil(t, cmd.Flags().Parse(tt.flagArgs))
+
+			budget, action := newBudget(cmd, &tt.config, tt.commandLine)
+			assert.Equal(t, tt.wantBudget, budget)
+			assert.Equal(t, tt.wantAction, action)
+		})
+	}
+}
@@ -1,33 +1,158 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/nextrevision/traci/providers"
 	"github.com/spf13/cobra"
+	"strings"
+)
+
+// How the provider printed by detect was selected.
+const (
+	selectedByOverride  = "override"
+	selectedByDetection = "detection"
+	selectedByDefault   = "default"
 )
 
 var detectCmd = &cobra.Command{
 	Use:   "detect",
 	Short: "detect CI environment and print config",
-	Run:   doDetect,
-	Args:  cobra.MinimumNArgs(0),
-}
+	Long: `detect the CI environment and print what traci detected about the current CI run. The provider is the first
+one whose marker environment variables are set, unless TRACI_PROVIDER selects one.
 
-func doDetect(cmd *cobra.Command, args []string) {
-	provider := providers.DetectProvider()
-
-	fmt.Println("CI Settings")
-	fmt.Printf("  provider: %s\n", provider.GetCIName())
-	fmt.Printf("  service name: %s\n", provider.GetServiceName())
-	fmt.Printf("  span name: %s\n", provider.GetSpanName())
-	fmt.Printf("  pipeline id: %s\n", provider.GetPipelineID())
-	fmt.Printf("  job id: %s\n", provider.GetJobID())
-	fmt.Println("  attributes:")
-	for k, v := range provider.GetAttributes() {
-		fmt.Printf("    %s: %s\n", k, v)
-	}
+Examples:
+
+traci detect --explain
+
+TRACI_PROVIDER=github-actions traci detect --json`,
+	RunE: doDetect,
+	Args: cobra.MinimumNArgs(0),
 }
 
 func init() {
+	detectCmd.Flags().Bool("explain", false, "list every provider whose marker environment variables are set")
+	detectCmd.Flags().Bool("json", false, "print the detected settings as JSON")
+
+	// Don't print the usage for an unknown provider
+	detectCmd.SilenceUsage = true
+	// Handle errors ourselves
+	detectCmd.SilenceErrors = true
+
 	rootCmd.AddCommand(detectCmd)
 }
+
+type detectOutput struct {
+	Provider       string                `json:"provider"`
+	SelectedBy     string                `json:"selected_by"`
+	Matches        []providers.Detection `json:"matches"`
+	ServiceName    string                `json:"service_name"`
+	SpanName       string                `json:"span_name"`
+	PipelineID     string                `json:"pipeline_id"`
+	JobID          string                `json:"job_id"`
+	Attempt        int                   `json:"attempt"`
+	PipelineName   string                `json:"pipeline_name"`
+	StageName      string                `json:"stage_name"`
+	JobName        string                `json:"job_name"`
+	RunURL         string                `json:"run_url"`
+	JobURL         string                `json:"job_url"`
+	Actor          string                `json:"actor"`
+	TriggerEvent   string                `json:"trigger_event"`
+	RepositoryName string                `json:"repository_name"`
+	RepositoryURL  string                `json:"repository_url"`
+	Branch         string                `json:"branch"`
+	Tag            string                `json:"tag"`
+	CommitSHA      string                `json:"commit_sha"`
+	Attributes     map[string]string     `json:"attributes"`
+}
+
+func doDetect(cmd *cobra.Command, args []string) error {
+	traciConfig := getConfig()
+	provider, err := detectProvider(traciConfig)
+	if err != nil {
+		return err
+	}
+
+	matches := providers.Detect()
+	if matches == nil {
+		matches = []providers.Detection{}
+	}
+	selectedBy := selectedByDefault
+	if traciConfig.Provider != "" {
+		selectedBy = selectedByOverride
+	} else if len(matches) > 0 {
+		selectedBy = selectedByDetection
+	}
+
+	output := detectOutput{
+		Provider:       provider.GetCIName(),
+		SelectedBy:     selectedBy,
+		Matches:        matches,
+		ServiceName:    provider.GetServiceName(),
+		SpanName:       provider.GetSpanName(),
+		PipelineID:     provider.GetPipelineID(),
+		JobID:          provider.GetJobID(),
+		Attempt:        provider.GetAttempt(),
+		PipelineName:   provider.GetPipelineName(),
+		StageName:      provider.GetStageName(),
+		JobName:        provider.GetJobName(),
+		RunURL:         provider.GetRunURL(),
+		JobURL:         provider.GetJobURL(),
+		Actor:          provider.GetActor(),
+		TriggerEvent:   provider.GetTriggerEvent(),
+		RepositoryName: provider.GetRepositoryName(),
+		RepositoryURL:  provider.GetRepositoryURL(),
+		Branch:         provider.GetBranch(),
+		Tag:            provider.GetTag(),
+		CommitSHA:      provider.GetCommitSHA(),
+		Attributes:     provider.GetAttributes(),
+	}
+
+	w := cmd.OutOrStdout()
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	}
+
+	if explain, _ := cmd.Flags().GetBool("explain"); explain {
+		fmt.Fprintln(w, "Detection")
+		fmt.Fprintf(w, "  selected by: %s\n", output.SelectedBy)
+		if len(output.Matches) == 0 {
+			fmt.Fprintln(w, "  matched providers: none")
+		} else {
+			fmt.Fprintln(w, "  matched providers:")
+			for _, m := range output.Matches {
+				fmt.Fprintf(w, "    %s: %s\n", m.Name, strings.Join(m.EnvVars, ", "))
+			}
+		}
+		if len(output.Matches) > 1 && output.SelectedBy == selectedByDetection {
+			fmt.Fprintf(w, "  the first match is used, set TRACI_PROVIDER to one of %v to select another\n", providers.ProviderNames())
+		}
+	}
+
+	fmt.Fprintln(w, "CI Settings")
+	fmt.Fprintf(w, "  provider: %s\n", output.Provider)
+	fmt.Fprintf(w, "  service name: %s\n", output.ServiceName)
+	fmt.Fprintf(w, "  span name: %s\n", output.SpanName)
+	fmt.Fprintf(w, "  pipeline id: %s\n", output.PipelineID)
+	fmt.Fprintf(w, "  job id: %s\n", output.JobID)
+	fmt.Fprintf(w, "  attempt: %d\n", output.Attempt)
+	fmt.Fprintf(w, "  pipeline name: %s\n", output.PipelineName)
+	fmt.Fprintf(w, "  stage name: %s\n", output.StageName)
+	fmt.Fprintf(w, "  job name: %s\n", output.JobName)
+	fmt.Fprintf(w, "  run url: %s\n", output.RunURL)
+	fmt.Fprintf(w, "  job url: %s\n", output.JobURL)
+	fmt.Fprintf(w, "  actor: %s\n", output.Actor)
+	fmt.Fprintf(w, "  trigger event: %s\n", output.TriggerEvent)
+	fmt.Fprintf(w, "  repository name: %s\n", output.RepositoryName)
+	fmt.Fprintf(w, "  repository url: %s\n", output.RepositoryURL)
+	fmt.Fprintf(w, "  branch: %s\n", output.Branch)
+	fmt.Fprintf(w, "  tag: %s\n", output.Tag)
+	fmt.Fprintf(w, "  commit sha: %s\n", output.CommitSHA)
+	fmt.Fprintln(w, "  attributes:")
+	for k, v := range output.Attributes {
+		fmt.Fprintf(w, "    %s: %s\n", k, v)
+	}
+	return nil
+}
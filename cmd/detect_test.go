@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/nextrevision/traci/providers"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestDetectProviderOverride(t *testing.T) {
+	for _, key := range []string{"CIRCLECI", "TRAVIS", "BITBUCKET_BUILD_NUMBER", "TF_BUILD", "JENKINS_URL"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("GITHUB_ACTION", "run")
+
+	tests := []struct {
+		name           string
+		provider       string
+		wantProvider   string
+		wantSelectedBy string
+		wantErr        bool
+	}{
+		{"Case for first match", "", "GitLab-CI", selectedByDetection, false},
+		{"Case for override", "github-actions", "GitHub-Actions", selectedByOverride, false},
+		{"Case for unknown override", "buildkite", "", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TRACI_PROVIDER", tc.provider)
+
+			stdout, _, errCode := execute(t, rootCmd, "detect", "--json")
+			if tc.wantErr {
+				assert.ErrorContains(t, errCode.Err, "unknown provider 'buildkite'")
+				return
+			}
+			assert.Nil(t, errCode.Err)
+
+			var output detectOutput
+			assert.Nil(t, json.Unmarshal([]byte(stdout), &output))
+			assert.Equal(t, tc.wantProvider, output.Provider)
+			assert.Equal(t, tc.wantSelectedBy, output.SelectedBy)
+			assert.Equal(t, []providers.Detection{
+				{Name: "GitLab-CI", EnvVars: []string{"GITLAB_CI"}},
+				{Name: "GitHub-Actions", EnvVars: []string{"GITHUB_ACTION"}},
+			}, output.Matches)
+		})
+	}
+}
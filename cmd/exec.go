@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"github.com/nextrevision/traci/config"
-	"github.com/nextrevision/traci/providers"
+	"github.com/nextrevision/traci/history"
+	"github.com/nextrevision/traci/procwatch"
 	"github.com/nextrevision/traci/tracing"
 	"github.com/spf13/cobra"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
-	"log/slog"
+	"go.opentelemetry.io/otel/trace"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -51,56 +52,35 @@ func runCommand(cmd *cobra.Command, args []string) error {
 
 	traciConfig := getConfig()
 
-	ciProvider := providers.DetectProvider()
+	ciProvider, err := resumeProvider(traciConfig)
+	if err != nil {
+		return NewErrorCode(1, err)
+	}
 
 	command := args[0]
 	commandPath, _ := exec.LookPath(command)
 
-	serviceName := ciProvider.GetServiceName()
-	if traciConfig.ServiceName != "" {
-		serviceName = traciConfig.ServiceName
-	}
-
-	spanName := fmt.Sprintf("%s:%s", ciProvider.GetSpanName(), command)
-	if traciConfig.SpanName != "" {
-		spanName = traciConfig.SpanName
-	}
+	serviceName := newServiceName(traciConfig, ciProvider)
+	spanName := newSpanName(traciConfig, ciProvider, command)
 
-	// Set resource attributes on the span
-	var resourceAttributes []attribute.KeyValue
-	resourceAttributes = append(resourceAttributes, semconv.ProcessExecutableName(command))
-	resourceAttributes = append(resourceAttributes, semconv.ProcessExecutablePath(commandPath))
-	resourceAttributes = append(resourceAttributes, tracing.AttributeMapToKeyValue(ciProvider.GetAttributes())...)
-	resourceAttributes = append(resourceAttributes, attribute.String("traci.ci.provider", ciProvider.GetCIName()))
-	resourceAttributes = append(resourceAttributes, attribute.String("traci.boundary", traciConfig.TraceBoundary))
-	resourceAttributes = append(resourceAttributes, attribute.String("traci.version", rootCmd.Version))
+	// Describe the command on the resource, or on the span in the span attribute mode
+	var commandAttributes []attribute.KeyValue
+	commandAttributes = append(commandAttributes, semconv.ProcessExecutableName(command))
+	commandAttributes = append(commandAttributes, semconv.ProcessExecutablePath(commandPath))
 
 	// Add command args as a process attribute to the span if specified
 	if traciConfig.TagCommandArgs && len(args) > 1 {
-		resourceAttributes = append(resourceAttributes, semconv.ProcessCommandArgs(args[1:]...))
+		commandAttributes = append(commandAttributes, semconv.ProcessCommandArgs(args[1:]...))
 	}
 
-	// If the TRACEPARENT environment variable is set, use it as the parent trace
-	traceCtx, err := tracing.NewContextFromEnvTraceParent(ctx)
-	if err != nil {
-		var traceDeterministicString string
-		switch traciConfig.TraceBoundary {
-		case string(config.TraceBoundaryPipeline):
-			traceDeterministicString = ciProvider.GetPipelineID()
-		case string(config.TraceBoundaryJob):
-			traceDeterministicString = ciProvider.GetJobID()
-		default:
-			traceDeterministicString = ciProvider.GetPipelineID()
-		}
-		traceCtx = tracing.NewContextFromDeterministicString(traceDeterministicString)
-	}
+	traceCtx := newTraceContext(ctx, traciConfig, ciProvider)
 
-	traceProvider := tracing.NewTraceProvider(traceCtx, serviceName, resourceAttributes)
+	traceProvider := tracing.NewTraceProvider(traceCtx, newResource(traceCtx, traciConfig, ciProvider, commandAttributes), newTraceProviderOptions(traciConfig, ciProvider, commandAttributes)...)
 
 	tracer := tracing.NewTracer(serviceName, traceProvider)
 
 	// Start a trace
-	spanCtx, span := tracer.Start(traceCtx, spanName)
+	spanCtx, span := tracer.Start(traceCtx, spanName, trace.WithLinks(newSpanLinks(traciConfig, ciProvider)...))
 
 	var child *exec.Cmd
 	if len(args) > 1 {
@@ -114,14 +94,8 @@ func runCommand(cmd *cobra.Command, args []string) error {
 	child.Stdout = cmd.OutOrStdout()
 	child.Stderr = cmd.ErrOrStderr()
 
-	// Replace TRACEPARENT in the environment with one from this span
-	child.Env = []string{}
-	for _, env := range os.Environ() {
-		if !strings.HasPrefix(env, fmt.Sprintf("%s=", tracing.TraceParentKey)) {
-			child.Env = append(child.Env, env)
-		}
-	}
-	child.Env = append(child.Env, fmt.Sprintf("%s=%s", tracing.TraceParentKey, tracing.GenTraceParentString(span.SpanContext())))
+	// Replace TRACEPARENT and other propagation variables in the environment with ones from this span
+	child.Env = newChildEnv(spanCtx)
 
 	// Forward CTRL-C (SIGINT) to the child process to attempt graceful shutdown
 	signals := make(chan os.Signal, 10)
@@ -133,41 +107,54 @@ func runCommand(cmd *cobra.Command, args []string) error {
 		close(signalsDone)
 	}()
 
-	// Run the child process and record any errors
-	if err = child.Run(); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		slog.Debug(err.Error())
+	// Run the child process, optionally watching its process tree, and record any errors
+	var watcher *procwatch.Watcher
+	started := time.Now()
+	err = child.Start()
+	if err == nil {
+		if traciConfig.ProcessTree {
+			watcher = procwatch.NewWatcher(child.Process.Pid, traciConfig.ProcessTreeInterval)
+			watcher.Start()
+		}
+		err = child.Wait()
+	}
+	duration := time.Since(started)
+	if watcher != nil {
+		procwatch.Emit(spanCtx, tracer, watcher.Stop(), traciConfig.ProcessTreeThreshold)
 	}
 
 	errCode := ErrorCode{
 		Code: child.ProcessState.ExitCode(),
 		Err:  err,
 	}
+	span.SetAttributes(tracing.ProcessExitCodeKey.Int(errCode.Code))
 
-	// Send the span to the collector and force a shutdown of the TraceProvider with a timeout
-	sent := make(chan bool, 1)
-	go func() {
-		span.End()
-
-		ctxTimeout, cancel := context.WithTimeout(ctx, time.Millisecond*100)
-		defer cancel()
+	commandLine := strings.Join(tracing.RedactArgs(args), " ")
+	setExitStatus(span, traciConfig, commandLine, errCode.Code, err)
+	if traciConfig.HistoryDir != "" {
+		key := history.Key{Service: serviceName, Span: spanName, Command: commandLine}
+		recordHistory(traciConfig.HistoryDir, key, duration, errCode.Code, span)
+	}
 
-		err := traceProvider.ForceFlush(ctxTimeout)
-		if err != nil {
-			slog.Debug(err.Error())
-		}
-		err = traceProvider.Shutdown(ctxTimeout)
-		if err != nil {
-			slog.Debug(err.Error())
+	// Enforce the duration budget, failing a successful command in fail mode
+	if budget, action := newBudget(cmd, traciConfig, commandLine); budget > 0 && duration > budget {
+		budgetErr := exceedBudget(span, budget, action, duration)
+		if action == config.BudgetActionFail && errCode.Code == 0 {
+			span.SetStatus(codes.Error, budgetErr.Error())
+			errCode = ErrorCode{Code: 1, Err: budgetErr}
+		} else {
+			fmt.Fprintf(cmd.ErrOrStderr(), "WARN %s\n", budgetErr)
 		}
-		sent <- true
-	}()
-
-	select {
-	case <-sent:
-		return &errCode
-	case <-time.After(500 * time.Millisecond): // TODO Make configurable
-		return &errCode
 	}
+
+	// Send the span to the collector and force a shutdown of the TraceProvider with a timeout
+	shutdownTraceProvider(ctx, traceProvider, time.Millisecond*100, 500*time.Millisecond, span) // TODO Make configurable
+
+	return &errCode
+}
+
+// newChildEnv returns the current environment with the propagation variables, such as TRACEPARENT, replaced by those
+// of the span in ctx.
+func newChildEnv(ctx context.Context) []string {
+	return tracing.InjectEnv(ctx, os.Environ())
 }
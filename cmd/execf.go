@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/nextrevision/traci/config"
+	"github.com/nextrevision/traci/procwatch"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -27,9 +28,26 @@ func init() {
 	// Handle errors ourselves
 	execfCmd.SilenceErrors = true
 
+	var budgetActionValue = &EnumValue{
+		Allowed: []string{
+			string(config.BudgetActionWarn),
+			string(config.BudgetActionFail),
+		},
+		Value: string(config.BudgetActionWarn),
+	}
+
+	var attributeModeValue = &EnumValue{
+		Allowed: []string{
+			string(config.AttributeModeResource),
+			string(config.AttributeModeSpan),
+		},
+		Value: string(config.AttributeModeResource),
+	}
+
 	var traceBoundaryValue = &EnumValue{
 		Allowed: []string{
 			string(config.TraceBoundaryPipeline),
+			string(config.TraceBoundaryStage),
 			string(config.TraceBoundaryJob),
 		},
 	}
@@ -38,11 +56,45 @@ func init() {
 	execfCmd.Flags().StringP("service-name", "n", "", "name of the service")
 	execfCmd.Flags().VarP(traceBoundaryValue, "trace-boundary", "t", "limit the trace to a pipeline, stage or job")
 	execfCmd.Flags().Bool("tag-command-args", false, "tag spans with the full list of command arguments")
+	execfCmd.Flags().Bool("process-tree", false, "emit spans for descendant processes of the command (linux only)")
+	execfCmd.Flags().Duration("process-tree-interval", procwatch.DefaultInterval, "interval between polls of the process tree")
+	execfCmd.Flags().Duration("process-tree-threshold", procwatch.DefaultThreshold, "minimum lifetime of a descendant process to emit a span")
+	execfCmd.Flags().String("spool-dir", "", "directory to additionally record spans in as OTLP JSON")
+	execfCmd.Flags().String("history-dir", "", "directory to keep a history of command durations in")
+	execfCmd.Flags().Duration("budget", 0, "duration the command is expected to finish within")
+	execfCmd.Flags().Var(budgetActionValue, "budget-action", "action when the command exceeds its budget, warn or fail")
+	execfCmd.Flags().IntSlice("ok-exit-codes", []int{0}, "exit codes which do not mark the span as an error")
+	execfCmd.Flags().StringSlice("baggage", nil, "baggage entries to propagate to the command as key=value")
+	execfCmd.Flags().String("upstream-pipeline-id", "", "pipeline ID of the pipeline which triggered this one")
+	execfCmd.Flags().String("upstream-traceparent", "", "traceparent of the upstream job which triggered this pipeline to link to")
+	execfCmd.Flags().Bool("legacy-attributes", true, "also tag spans with the CI provider's own attribute keys")
+	execfCmd.Flags().Var(attributeModeValue, "attribute-mode", "record per-invocation attributes on the resource or on spans")
+	execfCmd.Flags().String("session-id", "", "pipeline ID of local runs outside of CI, overriding the session file")
+	execfCmd.Flags().String("session-file", "", "file of the local session started by traci session start")
+	execfCmd.Flags().String("provider", "", "name of the CI provider to use instead of detecting it")
+	execfCmd.Flags().Bool("stage-spans", false, "parent job spans under the stage spans emitted by traci stage end")
 
 	viper.BindPFlag("span_name", execfCmd.Flags().Lookup("span-name"))
 	viper.BindPFlag("service_name", execfCmd.Flags().Lookup("service-name"))
 	viper.BindPFlag("trace_boundary", execfCmd.Flags().Lookup("trace-boundary"))
 	viper.BindPFlag("tag_command_args", execfCmd.Flags().Lookup("tag-command-args"))
+	viper.BindPFlag("process_tree", execfCmd.Flags().Lookup("process-tree"))
+	viper.BindPFlag("process_tree_interval", execfCmd.Flags().Lookup("process-tree-interval"))
+	viper.BindPFlag("process_tree_threshold", execfCmd.Flags().Lookup("process-tree-threshold"))
+	viper.BindPFlag("spool_dir", execfCmd.Flags().Lookup("spool-dir"))
+	viper.BindPFlag("history_dir", execfCmd.Flags().Lookup("history-dir"))
+	viper.BindPFlag("budget", execfCmd.Flags().Lookup("budget"))
+	viper.BindPFlag("budget_action", execfCmd.Flags().Lookup("budget-action"))
+	viper.BindPFlag("ok_exit_codes", execfCmd.Flags().Lookup("ok-exit-codes"))
+	viper.BindPFlag("baggage", execfCmd.Flags().Lookup("baggage"))
+	viper.BindPFlag("upstream_pipeline_id", execfCmd.Flags().Lookup("upstream-pipeline-id"))
+	viper.BindPFlag("upstream_traceparent", execfCmd.Flags().Lookup("upstream-traceparent"))
+	viper.BindPFlag("legacy_attributes", execfCmd.Flags().Lookup("legacy-attributes"))
+	viper.BindPFlag("attribute_mode", execfCmd.Flags().Lookup("attribute-mode"))
+	viper.BindPFlag("session_id", execfCmd.Flags().Lookup("session-id"))
+	viper.BindPFlag("session_file", execfCmd.Flags().Lookup("session-file"))
+	viper.BindPFlag("provider", execfCmd.Flags().Lookup("provider"))
+	viper.BindPFlag("stage_spans", execfCmd.Flags().Lookup("stage-spans"))
 
 	rootCmd.AddCommand(execfCmd)
 }
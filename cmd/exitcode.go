@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/nextrevision/traci/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"os/exec"
+	"slices"
+)
+
+// exitDescriptionKey records the description of an exit code mapped by an exit code rule.
+const exitDescriptionKey = attribute.Key("traci.exit.description")
+
+// setExitStatus sets the status of span from the result of running commandLine. The first exit code rule matching
+// the exit code, including 0, sets the status and description, otherwise exit codes other than the allowed exit codes
+// mark the span as an error. Commands which could not be started are always errors.
+func setExitStatus(span trace.Span, traciConfig *config.Config, commandLine string, exitCode int, err error) {
+	var exitErr *exec.ExitError
+	if err != nil {
+		slog.Debug(err.Error())
+		if !errors.As(err, &exitErr) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return
+		}
+	}
+
+	for _, rule := range traciConfig.ExitCodes {
+		if rule.Code != exitCode || (rule.Command != "" && !matchCommand(rule.Command, commandLine)) {
+			continue
+		}
+		if rule.Description != "" {
+			span.SetAttributes(exitDescriptionKey.String(rule.Description))
+		}
+		switch rule.Status {
+		case config.SpanStatusOk:
+			span.SetStatus(codes.Ok, "")
+		case config.SpanStatusUnset:
+		default:
+			description := rule.Description
+			if err != nil {
+				span.RecordError(err)
+				if description == "" {
+					description = err.Error()
+				}
+			} else if description == "" {
+				description = "exit status 0"
+			}
+			span.SetStatus(codes.Error, description)
+		}
+		return
+	}
+
+	if err == nil || slices.Contains(traciConfig.OkExitCodes, exitCode) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
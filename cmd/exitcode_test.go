@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/nextrevision/traci/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"os/exec"
+	"testing"
+)
+
+func TestSetExitStatus(t *testing.T) {
+	exitErr := exec.Command("/bin/sh", "-c", "exit 2").Run()
+	rules := []config.ExitCodeRule{
+		{Code: 2, Command: "terraform plan*", Status: config.SpanStatusOk, Description: "changes present"},
+		{Code: 2, Command: "diff", Status: config.SpanStatusError, Description: "trouble"},
+	}
+
+	tests := []struct {
+		name            string
+		config          config.Config
+		commandLine     string
+		exitCode        int
+		err             error
+		wantStatus      sdktrace.Status
+		wantDescription string
+	}{
+		{
+			name:        "Case for success",
+			config:      config.Config{},
+			commandLine: "true",
+			exitCode:    0,
+			err:         nil,
+			wantStatus:  sdktrace.Status{Code: codes.Unset},
+		},
+		{
+			name:        "Case for non-zero exit code",
+			config:      config.Config{},
+			commandLine: "sh",
+			exitCode:    2,
+			err:         exitErr,
+			wantStatus:  sdktrace.Status{Code: codes.Error, Description: "exit status 2"},
+		},
+		{
+			name:        "Case for allowed exit code",
+			config:      config.Config{OkExitCodes: []int{0, 2}},
+			commandLine: "sh",
+			exitCode:    2,
+			err:         exitErr,
+			wantStatus:  sdktrace.Status{Code: codes.Unset},
+		},
+		{
+			name:        "Case for command which could not start",
+			config:      config.Config{OkExitCodes: []int{-1}},
+			commandLine: "missing",
+			exitCode:    -1,
+			err:         errors.New("executable file not found"),
+			wantStatus:  sdktrace.Status{Code: codes.Error, Description: "executable file not found"},
+		},
+		{
+			name:            "Case for rule mapping to ok",
+			config:          config.Config{ExitCodes: rules},
+			commandLine:     "terraform plan -detailed-exitcode",
+			exitCode:        2,
+			err:             exitErr,
+			wantStatus:      sdktrace.Status{Code: codes.Ok},
+			wantDescription: "changes present",
+		},
+		{
+			name:            "Case for rule mapping to error over allowed exit codes",
+			config:          config.Config{OkExitCodes: []int{2}, ExitCodes: rules},
+			commandLine:     "diff a b",
+			exitCode:        2,
+			err:             exitErr,
+			wantStatus:      sdktrace.Status{Code: codes.Error, Description: "trouble"},
+			wantDescription: "trouble",
+		},
+		{
+			name:            "Case for rule mapping success to error",
+			config:          config.Config{ExitCodes: []config.ExitCodeRule{{Code: 0, Command: "grep*", Status: config.SpanStatusError, Description: "match found"}}},
+			commandLine:     "grep -q TODO main.go",
+			exitCode:        0,
+			err:             nil,
+			wantStatus:      sdktrace.Status{Code: codes.Error, Description: "match found"},
+			wantDescription: "match found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+			_, span := tracer.Start(context.Background(), "test")
+
+			setExitStatus(span, &tt.config, tt.commandLine, tt.exitCode, tt.err)
+			span.End()
+
+			got := recorder.Ended()[0]
+			assert.Equal(t, tt.wantStatus, got.Status())
+			description := ""
+			for _, attr := range got.Attributes() {
+				if attr.Key == exitDescriptionKey {
+					description = attr.Value.AsString()
+				}
+			}
+			assert.Equal(t, tt.wantDescription, description)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nextrevision/traci/spool"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export spans recorded locally to other formats",
+}
+
+var exportChromeCmd = &cobra.Command{
+	Use:   "chrome [path...]",
+	Short: "merge spooled spans into a Chrome trace-event file",
+	Long: `merge spooled spans into a single Chrome trace-event file which can be loaded in Perfetto or chrome://tracing.
+Paths can be spool directories or OTLP JSON files and default to the configured spool directory.
+
+Examples:
+
+TRACI_SPOOL_DIR=.traci traci exec make build; traci export chrome -o trace.json
+
+traci export chrome -o - spool/ collector-export.json > trace.json`,
+	RunE: runExportChrome,
+}
+
+func init() {
+	exportChromeCmd.Flags().StringP("output", "o", "traci-trace.json", "file to write the trace to, or - for stdout")
+
+	exportCmd.AddCommand(exportChromeCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExportChrome(cmd *cobra.Command, args []string) error {
+	records, err := readSpool(args)
+	if err != nil {
+		return err
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	var w io.Writer = cmd.OutOrStdout()
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := spool.WriteChromeTrace(w, records); err != nil {
+		return err
+	}
+	if output != "-" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "wrote %d spans to %s\n", len(records), output)
+	}
+	return nil
+}
+
+// readSpool reads the spans in paths, defaulting to the configured spool directory when no paths are given.
+func readSpool(paths []string) ([]spool.Record, error) {
+	if len(paths) == 0 {
+		spoolDir := getConfig().SpoolDir
+		if spoolDir == "" {
+			return nil, errors.New("no paths given and no spool directory configured; set TRACI_SPOOL_DIR")
+		}
+		paths = []string{spoolDir}
+	}
+	return spool.Read(paths...)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nextrevision/traci/tracing"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"os"
+)
+
+const githubOutputKey = "GITHUB_OUTPUT"
+
+// Formats of export-context.
+const (
+	contextFormatDotenv       = "dotenv"
+	contextFormatGitHubOutput = "github-output"
+	contextFormatJSON         = "json"
+)
+
+var exportContextCmd = &cobra.Command{
+	Use:   "export-context",
+	Short: "write the trace context of the current job for downstream jobs",
+	Long: `write the TRACEPARENT of the current job span, which traci job end emits, so the job spans of downstream jobs
+can be parented under it. The job span is derived from the CI provider's pipeline and job IDs, or the trace ID is taken
+from the TRACEPARENT of the job if set.
+
+The dotenv format can be published as a GitLab dotenv report, which sets TRACEPARENT in the jobs needing this job. The
+github-output format appends a traceparent output to $GITHUB_OUTPUT, which downstream jobs can set as their TRACEPARENT.
+
+Examples:
+
+traci export-context --format dotenv -o trace.env
+
+traci export-context --format github-output
+
+traci export-context --format json`,
+	RunE: runExportContext,
+}
+
+func init() {
+	var formatValue = &EnumValue{
+		Allowed: []string{
+			contextFormatDotenv,
+			contextFormatGitHubOutput,
+			contextFormatJSON,
+		},
+		Value: contextFormatDotenv,
+	}
+
+	exportContextCmd.Flags().VarP(formatValue, "format", "f", "output format, dotenv, github-output or json")
+	exportContextCmd.Flags().StringP("output", "o", "", "file to append the context to, defaults to stdout or $GITHUB_OUTPUT")
+
+	// Don't print the usage for an unknown provider
+	exportContextCmd.SilenceUsage = true
+	// Handle errors ourselves
+	exportContextCmd.SilenceErrors = true
+
+	rootCmd.AddCommand(exportContextCmd)
+}
+
+func runExportContext(cmd *cobra.Command, args []string) error {
+	format := cmd.Flags().Lookup("format").Value.String()
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" && format == contextFormatGitHubOutput {
+		output = os.Getenv(githubOutputKey)
+		if output == "" {
+			return fmt.Errorf("%s is not set", githubOutputKey)
+		}
+	}
+
+	traciConfig := getConfig()
+	ciProvider, err := detectProvider(traciConfig)
+	if err != nil {
+		return err
+	}
+	traceCtx := newTraceContext(cmd.Context(), traciConfig, ciProvider)
+	spanContext := trace.SpanContextFromContext(traceCtx)
+	if !spanContext.IsValid() {
+		return errors.New("could not determine the trace context of the job")
+	}
+	traceParent := tracing.GenTraceParentString(spanContext)
+
+	var w io.Writer = cmd.OutOrStdout()
+	if output != "" {
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case contextFormatGitHubOutput:
+		_, err := fmt.Fprintf(w, "traceparent=%s\n", traceParent)
+		return err
+	case contextFormatJSON:
+		return json.NewEncoder(w).Encode(map[string]string{
+			"traceparent": traceParent,
+			"trace_id":    spanContext.TraceID().String(),
+			"span_id":     spanContext.SpanID().String(),
+		})
+	default:
+		_, err := fmt.Fprintf(w, "%s=%s\n", tracing.TraceParentKey, traceParent)
+		return err
+	}
+}
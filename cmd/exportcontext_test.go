@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"github.com/nextrevision/traci/tracing"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportContextCmd(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_PIPELINE_ID", "100")
+	t.Setenv("CI_JOB_ID", "7")
+	t.Setenv("TRACEPARENT", "")
+	t.Setenv("TRACI_TRACE_BOUNDARY", "pipeline")
+	githubOutput := filepath.Join(t.TempDir(), "github_output")
+	t.Setenv("GITHUB_OUTPUT", githubOutput)
+
+	// The trace ID is derived from the pipeline ID and the span ID from the job ID
+	traceParent := "00-f899139df5e1059396431415e770c6dd-8f14e45fceea167a-01"
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdout string
+	}{
+		{
+			name:   "dotenv format",
+			args:   []string{"export-context", "--format", "dotenv"},
+			stdout: "TRACEPARENT=" + traceParent,
+		},
+		{
+			name:   "json format",
+			args:   []string{"export-context", "--format", "json"},
+			stdout: `{"span_id":"8f14e45fceea167a","trace_id":"f899139df5e1059396431415e770c6dd","traceparent":"` + traceParent + `"}`,
+		},
+		{
+			name:   "github-output format",
+			args:   []string{"export-context", "--format", "github-output"},
+			stdout: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout, _, errCode := execute(t, rootCmd, tc.args...)
+			assert.Nil(t, errCode.Err)
+			assert.Equal(t, tc.stdout, stdout)
+		})
+	}
+
+	data, err := os.ReadFile(githubOutput)
+	assert.Nil(t, err)
+	assert.Equal(t, "traceparent="+traceParent+"\n", string(data))
+}
+
+func TestExportContextStageBoundary(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_PIPELINE_ID", "100")
+	t.Setenv("CI_JOB_STAGE", "test")
+	t.Setenv("CI_JOB_ID", "7")
+	t.Setenv("TRACEPARENT", "")
+	t.Setenv("TRACI_TRACE_BOUNDARY", "stage")
+
+	// The trace ID is derived from the stage ID and the span ID from the job ID
+	traceParent := tracing.GenTraceParentString(tracing.NewDeterministicSpanContext("100-test", "7"))
+
+	stdout, _, errCode := execute(t, rootCmd, "export-context", "--format", "dotenv")
+	assert.Nil(t, errCode.Err)
+	assert.Equal(t, "TRACEPARENT="+traceParent, stdout)
+}
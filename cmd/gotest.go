@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"github.com/nextrevision/traci/ingest"
+	"github.com/nextrevision/traci/providers"
+	"github.com/nextrevision/traci/tracing"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"os/exec"
+)
+
+var goTestCmd = &cobra.Command{
+	Use:   "go-test",
+	Short: "run go test and record a span for each package and test",
+	Long: `run go test with -json and record a span for each package, test and subtest under a span for the go test command.
+The regular go test output is printed and the exit code of go test is returned.
+
+Examples:
+
+traci go-test -- ./...
+
+traci go-test -- -race -run TestFoo ./pkg/...`,
+	RunE: runGoTest,
+}
+
+func init() {
+	// Disable printing usage due to the return type always being a non-nil error
+	goTestCmd.SilenceUsage = true
+	// Handle errors ourselves
+	goTestCmd.SilenceErrors = true
+
+	rootCmd.AddCommand(goTestCmd)
+}
+
+func runGoTest(cmd *cobra.Command, args []string) error {
+	traciConfig := getConfig()
+
+	var errCode *ErrorCode
+	err := withTracer(cmd, func(ctx context.Context, tracer trace.Tracer, ciProvider providers.Provider) error {
+		spanName := newSpanName(traciConfig, ciProvider, "go test")
+		spanCtx, span := tracer.Start(ctx, spanName, trace.WithLinks(newSpanLinks(traciConfig, ciProvider)...))
+		defer span.End()
+
+		child := exec.CommandContext(spanCtx, "go", append([]string{"test", "-json"}, args...)...)
+		child.Stdin = cmd.InOrStdin()
+		child.Stderr = cmd.ErrOrStderr()
+		child.Env = newChildEnv(spanCtx)
+
+		stdout, err := child.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		if err = child.Start(); err == nil {
+			if ingestErr := ingest.GoTest(spanCtx, tracer, stdout, cmd.OutOrStdout()); ingestErr != nil {
+				slog.Debug(ingestErr.Error())
+			}
+			err = child.Wait()
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			slog.Debug(err.Error())
+		}
+
+		errCode = &ErrorCode{
+			Code: child.ProcessState.ExitCode(),
+			Err:  err,
+		}
+		span.SetAttributes(tracing.ProcessExitCodeKey.Int(errCode.Code))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errCode
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nextrevision/traci/history"
+	"github.com/nextrevision/traci/summary"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "inspect the local history of command durations",
+}
+
+var historyRegressionsCmd = &cobra.Command{
+	Use:   "regressions",
+	Short: "list commands whose latest run was slower than their baseline",
+	Long: `list commands whose latest successful run took longer than a percentile of their previous successful runs, as
+recorded by traci exec when TRACI_HISTORY_DIR is set.
+
+Examples:
+
+traci history regressions
+
+traci history regressions --percentile 95 --fail`,
+	RunE: runHistoryRegressions,
+}
+
+func init() {
+	historyRegressionsCmd.Flags().Float64("percentile", history.DefaultPercentile, "percentile of previous runs the latest run has to exceed")
+	historyRegressionsCmd.Flags().Int("window", history.DefaultWindow, "number of previous successful runs to compare against")
+	historyRegressionsCmd.Flags().Int("min-samples", history.DefaultMinSamples, "number of previous successful runs required to compare against")
+	historyRegressionsCmd.Flags().Bool("fail", false, "exit with a non-zero code when regressions are found")
+
+	historyCmd.AddCommand(historyRegressionsCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistoryRegressions(cmd *cobra.Command, args []string) error {
+	historyDir := getConfig().HistoryDir
+	if historyDir == "" {
+		return errors.New("no history directory configured; set TRACI_HISTORY_DIR")
+	}
+
+	entries, err := history.Open(historyDir).Read()
+	if err != nil {
+		return err
+	}
+
+	percentile, _ := cmd.Flags().GetFloat64("percentile")
+	window, _ := cmd.Flags().GetInt("window")
+	minSamples, _ := cmd.Flags().GetInt("min-samples")
+	regressions := history.Regressions(entries, window, minSamples, percentile)
+
+	if len(regressions) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no regressions found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "SERVICE\tSPAN\tCOMMAND\tLATEST\tP50\tP%g\tSAMPLES\n", percentile)
+	for _, r := range regressions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			r.Latest.Service,
+			r.Latest.Span,
+			r.Latest.Command,
+			summary.FormatDuration(r.Latest.Duration),
+			summary.FormatDuration(r.Baseline.P50),
+			summary.FormatDuration(r.Baseline.Threshold),
+			r.Baseline.Samples,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if fail, _ := cmd.Flags().GetBool("fail"); fail {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return NewErrorCode(1, fmt.Errorf("%d regressions found", len(regressions)))
+	}
+	return nil
+}
+
+// recordHistory compares the run with the history of the command, tagging span with the baseline, and appends the run
+// to the history. Once the command has twice history.MaxEntriesPerKey runs, the history is trimmed, so it is only
+// rewritten every history.MaxEntriesPerKey runs. Errors are only logged so a broken history never fails the command.
+func recordHistory(historyDir string, key history.Key, duration time.Duration, exitCode int, span trace.Span) {
+	h := history.Open(historyDir)
+
+	entries, err := h.Read()
+	if err != nil {
+		slog.Debug(err.Error())
+	}
+	if baseline, ok := history.NewBaseline(entries, key, history.DefaultWindow, history.DefaultMinSamples, history.DefaultPercentile); ok {
+		span.SetAttributes(
+			history.BaselineP50Key.Float64(baseline.P50.Seconds()),
+			history.RegressedKey.Bool(exitCode == 0 && baseline.Regressed(duration)),
+		)
+	}
+
+	entry := history.Entry{Key: key, Time: time.Now().Add(-duration), Duration: duration, ExitCode: exitCode}
+	if err := h.Append(entry); err != nil {
+		slog.Debug(err.Error())
+		return
+	}
+
+	runs := 1
+	for _, e := range entries {
+		if e.Key == key {
+			runs++
+		}
+	}
+	if runs >= 2*history.MaxEntriesPerKey {
+		if err := h.Trim(history.MaxEntriesPerKey); err != nil {
+			slog.Debug(err.Error())
+		}
+	}
+}
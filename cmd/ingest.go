@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/nextrevision/traci/ingest"
+	"github.com/nextrevision/traci/providers"
+	"github.com/nextrevision/traci/tracing"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest",
+	Short: "convert test and build reports into spans",
+	Long: `convert test and build reports into spans of the current trace.
+Spans are parented under the span in the TRACEPARENT environment variable if set, otherwise under the CI trace.
+Reports are read from the given files, which may be glob patterns, or from stdin when no files are given.`,
+}
+
+var ingestGoTestCmd = &cobra.Command{
+	Use:   "gotest [file...]",
+	Short: "ingest a go test -json event stream",
+	Long: `ingest a go test -json event stream, creating a span for each package and a child span for each test and subtest.
+
+Examples:
+
+go test -json ./... > results.json; traci ingest gotest results.json
+
+go test -json ./... | traci ingest gotest`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngest(cmd, args, func(ctx context.Context, tracer trace.Tracer, r io.Reader, _ time.Time) error {
+			return ingest.GoTest(ctx, tracer, r, nil)
+		})
+	},
+}
+
+var ingestJUnitCmd = &cobra.Command{
+	Use:   "junit [file...]",
+	Short: "ingest JUnit XML reports",
+	Long: `ingest JUnit XML reports, creating a span for each testsuite and a child span for each testcase.
+Test case start times are reconstructed from the suite timestamp and the durations of the preceding test cases.
+
+Examples:
+
+traci ingest junit reports/*.xml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngest(cmd, args, ingest.JUnit)
+	},
+}
+
+var ingestTAPCmd = &cobra.Command{
+	Use:   "tap [file...]",
+	Short: "ingest TAP version 13 or 14 streams",
+	Long: `ingest TAP version 13 or 14 streams, creating a span for each test point with subtests nested under their parent.
+Tests end when their result is read, so pipe the test runner into traci for accurate timings.
+
+Examples:
+
+prove -v t/ | traci ingest tap
+
+node --test --test-reporter tap | traci ingest tap`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngest(cmd, args, ingest.TAP)
+	},
+}
+
+var ingestLibtestCmd = &cobra.Command{
+	Use:     "libtest [file...]",
+	Aliases: []string{"cargo", "nextest"},
+	Short:   "ingest libtest JSON output from cargo test or nextest",
+	Long: `ingest libtest JSON output from cargo test or nextest, creating a span for each test binary and a child span for each test.
+Tests start and end when their events are read, so pipe the test runner into traci for accurate timings.
+
+Examples:
+
+cargo test -- -Z unstable-options --format json --report-time | traci ingest libtest
+
+NEXTEST_EXPERIMENTAL_LIBTEST_JSON=1 cargo nextest run --message-format libtest-json | traci ingest libtest`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngest(cmd, args, ingest.Libtest)
+	},
+}
+
+var ingestBazelCmd = &cobra.Command{
+	Use:   "bazel-bep [file...]",
+	Short: "ingest a Bazel JSON build event protocol file",
+	Long: `ingest a Bazel JSON build event protocol file, creating a span for the invocation with child spans for each target,
+and spans for each action and test result below their target.
+
+Examples:
+
+bazel test --build_event_json_file=build_events.json //...; traci ingest bazel-bep build_events.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngest(cmd, args, ingest.Bazel)
+	},
+}
+
+var ingestChromeTraceCmd = &cobra.Command{
+	Use:   "chrome-trace [file...]",
+	Short: "ingest Chrome trace-event files",
+	Long: `ingest Chrome trace-event files, creating a span for each complete event and each pair of begin and end events.
+Events on the same thread are nested by time. Relative timestamps are placed so the trace ends when the file was written.
+
+Examples:
+
+traci ingest chrome-trace trace.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngest(cmd, args, ingest.ChromeTrace)
+	},
+}
+
+var ingestNinjaLogCmd = &cobra.Command{
+	Use:   "ninja-log [file...]",
+	Short: "ingest the most recent build of a .ninja_log file",
+	Long: `ingest the most recent build of a .ninja_log file, creating a span for each build edge.
+The build is placed to end when the log was last written.
+
+Examples:
+
+traci ingest ninja-log build/.ninja_log`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngest(cmd, args, ingest.NinjaLog)
+	},
+}
+
+var ingestBuildKitCmd = &cobra.Command{
+	Use:   "buildkit-rawjson [file...]",
+	Short: "ingest the rawjson progress output of docker buildx",
+	Long: `ingest the rawjson progress output of docker buildx, creating a span for each build step with child spans for its
+progress statuses such as layer downloads.
+
+Examples:
+
+docker buildx build --progress rawjson . 2>&1 | traci ingest buildkit-rawjson`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngest(cmd, args, ingest.BuildKitRawJSON)
+	},
+}
+
+func init() {
+	ingestCmd.AddCommand(ingestBazelCmd)
+	ingestCmd.AddCommand(ingestBuildKitCmd)
+	ingestCmd.AddCommand(ingestChromeTraceCmd)
+	ingestCmd.AddCommand(ingestGoTestCmd)
+	ingestCmd.AddCommand(ingestJUnitCmd)
+	ingestCmd.AddCommand(ingestTAPCmd)
+	ingestCmd.AddCommand(ingestLibtestCmd)
+	ingestCmd.AddCommand(ingestNinjaLogCmd)
+	rootCmd.AddCommand(ingestCmd)
+}
+
+// ingestFunc records spans under ctx for the report read from r. reportTime is the time a report file was written, used
+// to place reports which only record durations or relative timestamps. It is zero for reports streamed from stdin.
+type ingestFunc func(ctx context.Context, tracer trace.Tracer, r io.Reader, reportTime time.Time) error
+
+// runIngest calls ingestFn for every file matching the patterns in args, or for stdin if none are given.
+func runIngest(cmd *cobra.Command, args []string, ingestFn ingestFunc) error {
+	var files []string
+	for _, pattern := range args {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no files match %s", pattern)
+		}
+		files = append(files, matches...)
+	}
+
+	return withTracer(cmd, func(ctx context.Context, tracer trace.Tracer, _ providers.Provider) error {
+		if len(files) == 0 {
+			return ingestFn(ctx, tracer, cmd.InOrStdin(), time.Time{})
+		}
+		for _, file := range files {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			reportTime := time.Now()
+			if info, err := f.Stat(); err == nil {
+				reportTime = info.ModTime()
+			}
+			err = ingestFn(ctx, tracer, f, reportTime)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+		return nil
+	})
+}
+
+// withTracer sets up a batching tracer for the detected CI provider and calls fn with the parent context of new spans
+// and the CI provider. All spans are flushed once fn returns.
+func withTracer(cmd *cobra.Command, fn func(ctx context.Context, tracer trace.Tracer, ciProvider providers.Provider) error) error {
+	ctx := cmd.Context()
+
+	traciConfig := getConfig()
+	ciProvider, err := resumeProvider(traciConfig)
+	if err != nil {
+		return err
+	}
+	serviceName := newServiceName(traciConfig, ciProvider)
+
+	traceCtx := newTraceContext(ctx, traciConfig, ciProvider)
+	traceProvider := tracing.NewBatchTraceProvider(traceCtx, newResource(traceCtx, traciConfig, ciProvider, nil), newTraceProviderOptions(traciConfig, ciProvider, nil)...)
+	tracer := tracing.NewTracer(serviceName, traceProvider)
+
+	err = fn(traceCtx, tracer, ciProvider)
+
+	shutdownTraceProvider(ctx, traceProvider, 5*time.Second, 10*time.Second)
+
+	return err
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nextrevision/traci/config"
+	"github.com/nextrevision/traci/providers"
+	"github.com/nextrevision/traci/tracing"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "record the span of the current CI job",
+}
+
+var jobStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "record the start of the current CI job",
+	Long: `record the start of the job span, which the spans of every traci command of the job are parented under. The first
+traci command of a job records it as well, so running traci job start as the first step only makes the job span
+include the steps before that command.`,
+	Args: cobra.NoArgs,
+	RunE: runJobStart,
+}
+
+var jobEndCmd = &cobra.Command{
+	Use:   "end",
+	Short: "emit the span of the current CI job",
+	Long: `emit the job span, which the spans of every traci command of the job and of the downstream jobs using its
+export-context are parented under. The span starts when the job was started by traci job start or its first traci
+command, and ends now. Run it as the last step of the job, for example in a GitLab CI after_script or a GitHub Actions
+step with if: always().
+
+Examples:
+
+traci job end
+
+traci job end --status error`,
+	Args: cobra.NoArgs,
+	RunE: runJobEnd,
+}
+
+func init() {
+	jobEndCmd.Flags().Var(newSpanStatusValue(), "status", "status of the job span, unset, ok or error")
+
+	// Don't print the usage for an unknown provider or outside of CI, and handle errors ourselves
+	jobStartCmd.SilenceUsage = true
+	jobStartCmd.SilenceErrors = true
+	jobEndCmd.SilenceUsage = true
+	jobEndCmd.SilenceErrors = true
+
+	jobCmd.AddCommand(jobStartCmd)
+	jobCmd.AddCommand(jobEndCmd)
+	rootCmd.AddCommand(jobCmd)
+}
+
+// jobState is recorded by the first invocation of traci in a CI job, so traci job end can emit the job span.
+type jobState struct {
+	Started time.Time `json:"started"`
+	// Parent is the traceparent of the trace context found in the environment by the first invocation, which the job
+	// span is parented under, or empty if there was none.
+	Parent string `json:"parent,omitempty"`
+}
+
+// jobStateMaxAge is the age after which the state files of jobs which never ran traci job end are removed. It exceeds
+// the longest job timeout of the hosted CI providers, so only jobs which ended long ago lose their state.
+const jobStateMaxAge = 7 * 24 * time.Hour
+
+// jobStatePath returns the path of the state file of the current job in the temporary directory.
+func jobStatePath(ciProvider providers.Provider) string {
+	sum := md5.Sum([]byte(ciProvider.GetCIName() + "/" + ciProvider.GetJobID()))
+	return filepath.Join(os.TempDir(), fmt.Sprintf("traci-job-%x.json", sum))
+}
+
+// recordJobState returns the state of the job in path, which is recorded with parent as started at now unless an
+// earlier invocation already recorded it.
+func recordJobState(path string, parent string, now time.Time) (jobState, error) {
+	state, err := readJobState(path)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return state, err
+	}
+
+	state = jobState{Started: now, Parent: parent}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return state, err
+	}
+
+	// Link the complete file into place, so concurrent invocations never read a partial state and only the first wins
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return state, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return state, err
+	}
+	if err = os.Link(tmp.Name(), path); errors.Is(err, fs.ErrExist) {
+		return readJobState(path)
+	} else if err != nil {
+		return state, err
+	}
+
+	// Jobs which never run traci job end leave their state behind, which piles up on persistent runners
+	pruneJobStates(filepath.Dir(path), now.Add(-jobStateMaxAge))
+	return state, nil
+}
+
+// pruneJobStates removes the state files in dir which were last modified before cutoff.
+func pruneJobStates(dir string, cutoff time.Time) {
+	paths, _ := filepath.Glob(filepath.Join(dir, "traci-job-*.json"))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(path)
+		}
+	}
+}
+
+// readJobState reads the state of the job in path.
+func readJobState(path string) (jobState, error) {
+	var state jobState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	if err = json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("invalid job state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// newJobSpanContext returns the span context of the job span, whose span ID is derived from the job ID. Its trace ID
+// is the one of parent, or if parent is invalid, derived from the CI provider according to the trace boundary.
+func newJobSpanContext(traciConfig *config.Config, ciProvider providers.Provider, parent trace.SpanContext) trace.SpanContext {
+	traceID := traceIDString(traciConfig, ciProvider.GetPipelineID(), ciProvider.GetStageID(), ciProvider.GetJobID())
+	spanContext := tracing.NewDeterministicSpanContext(traceID, ciProvider.GetJobID())
+	if parent.IsValid() {
+		spanContext = spanContext.WithTraceID(parent.TraceID()).WithTraceFlags(parent.TraceFlags())
+	}
+	return spanContext
+}
+
+// newJobSpanName returns the name of the job span, which is the job name of the CI provider if it has one.
+func newJobSpanName(ciProvider providers.Provider) string {
+	if name := ciProvider.GetJobName(); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s:job", ciProvider.GetSpanName())
+}
+
+func runJobStart(cmd *cobra.Command, args []string) error {
+	ciProvider, err := detectProvider(getConfig())
+	if err != nil {
+		return err
+	}
+	if _, local := ciProvider.(providers.DefaultProvider); local {
+		return errors.New("no CI job detected, traci job only records the spans of CI jobs")
+	}
+
+	_, err = recordJobState(jobStatePath(ciProvider), envTraceParent(cmd.Context()), time.Now())
+	return err
+}
+
+func runJobEnd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	traciConfig := getConfig()
+	ciProvider, err := detectProvider(traciConfig)
+	if err != nil {
+		return err
+	}
+	if _, local := ciProvider.(providers.DefaultProvider); local {
+		return errors.New("no CI job detected, traci job only records the spans of CI jobs")
+	}
+
+	path := jobStatePath(ciProvider)
+	state, err := readJobState(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(cmd.ErrOrStderr(), "WARN no start of the job was recorded, the job span starts now")
+		state = jobState{Started: time.Now(), Parent: envTraceParent(ctx)}
+	} else if err != nil {
+		return err
+	} else {
+		defer os.Remove(path)
+	}
+
+	// The job span is parented under the trace context of the job, or else the stage span if stage spans are emitted
+	parent := tracing.ParseTraceParent(state.Parent)
+	if stageSpanContext, ok := newStageSpanContext(traciConfig, ciProvider); !parent.IsValid() && ok && traciConfig.StageSpans {
+		parent = stageSpanContext
+	}
+
+	status := config.SpanStatus(cmd.Flags().Lookup("status").Value.String())
+	emitSpan(ctx, traciConfig, ciProvider, newJobSpanContext(traciConfig, ciProvider, parent), parent, newJobSpanName(ciProvider), state.Started, status)
+	return nil
+}
+
+// emitSpan emits a span with the IDs of spanContext, which was already handed out as the parent of other spans, under
+// parent if it is valid.
+func emitSpan(ctx context.Context, traciConfig *config.Config, ciProvider providers.Provider, spanContext, parent trace.SpanContext, name string, started time.Time, status config.SpanStatus) {
+	parentCtx := ctx
+	if parent.IsValid() {
+		parentCtx = trace.ContextWithRemoteSpanContext(ctx, parent)
+	}
+
+	opts := append(newTraceProviderOptions(traciConfig, ciProvider, nil), tracing.WithSpanContextIDs(spanContext))
+	traceProvider := tracing.NewTraceProvider(parentCtx, newResource(parentCtx, traciConfig, ciProvider, nil), opts...)
+	tracer := tracing.NewTracer(newServiceName(traciConfig, ciProvider), traceProvider)
+
+	_, span := tracer.Start(parentCtx, name, trace.WithTimestamp(started))
+	switch status {
+	case config.SpanStatusOk:
+		span.SetStatus(codes.Ok, "")
+	case config.SpanStatusError:
+		span.SetStatus(codes.Error, fmt.Sprintf("%s failed", name))
+	}
+
+	shutdownTraceProvider(ctx, traceProvider, time.Millisecond*100, 500*time.Millisecond, span)
+}
+
+// newSpanStatusValue returns the value of a flag setting the status of a span.
+func newSpanStatusValue() *EnumValue {
+	return &EnumValue{
+		Allowed: []string{
+			string(config.SpanStatusUnset),
+			string(config.SpanStatusOk),
+			string(config.SpanStatusError),
+		},
+		Value: string(config.SpanStatusUnset),
+	}
+}
+
+// envTraceParent returns the traceparent of the trace context found in the environment, or an empty string.
+func envTraceParent(ctx context.Context) string {
+	traceCtx, err := tracing.NewContextFromEnv(ctx, os.Environ())
+	if err != nil {
+		return ""
+	}
+	return tracing.GenTraceParentString(trace.SpanContextFromContext(traceCtx))
+}
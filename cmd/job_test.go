@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"github.com/nextrevision/traci/config"
+	"github.com/nextrevision/traci/providers"
+	"github.com/nextrevision/traci/spool"
+	"github.com/nextrevision/traci/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewTraceContextJobSpan(t *testing.T) {
+	upstream := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	nested := "00-4bf92f3577b34da6a3ce929d0e0e4736-b7ad6b7169203331-01"
+	jobSpanContext := tracing.NewDeterministicSpanContext("100", "7")
+	downstreamSpanContext := jobSpanContext.WithTraceID(tracing.ParseTraceParent(upstream).TraceID())
+
+	tests := []struct {
+		name         string
+		traceParents []string
+		want         []trace.SpanContext
+	}{
+		{
+			name:         "Case for commands of a job",
+			traceParents: []string{"", ""},
+			want:         []trace.SpanContext{jobSpanContext, jobSpanContext},
+		},
+		{
+			name:         "Case for nested command",
+			traceParents: []string{"", nested},
+			want:         []trace.SpanContext{jobSpanContext, tracing.ParseTraceParent(nested)},
+		},
+		{
+			name:         "Case for downstream job",
+			traceParents: []string{upstream, upstream},
+			want:         []trace.SpanContext{downstreamSpanContext, downstreamSpanContext},
+		},
+		{
+			name:         "Case for nested command of downstream job",
+			traceParents: []string{upstream, nested},
+			want:         []trace.SpanContext{downstreamSpanContext, tracing.ParseTraceParent(nested)},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TMPDIR", t.TempDir())
+			t.Setenv("CI_PIPELINE_ID", "100")
+			t.Setenv("CI_JOB_ID", "7")
+			traciConfig := &config.Config{TraceBoundary: string(config.TraceBoundaryPipeline)}
+
+			for i, traceParent := range tc.traceParents {
+				t.Setenv("TRACEPARENT", traceParent)
+				traceCtx := newTraceContext(context.Background(), traciConfig, providers.GitLabCI{})
+				got := trace.SpanContextFromContext(traceCtx)
+				assert.Equal(t, tc.want[i].TraceID(), got.TraceID())
+				assert.Equal(t, tc.want[i].SpanID(), got.SpanID())
+			}
+		})
+	}
+}
+
+func TestRecordJobState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.json")
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	state, err := recordJobState(path, "", started)
+	assert.Nil(t, err)
+	assert.Equal(t, jobState{Started: started}, state)
+
+	// The first invocation of the job wins
+	state, err = recordJobState(path, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", started.Add(time.Minute))
+	assert.Nil(t, err)
+	assert.True(t, started.Equal(state.Started))
+	assert.Equal(t, "", state.Parent)
+}
+
+func TestRecordJobStatePrunesStaleStates(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	stale := filepath.Join(dir, "traci-job-stale.json")
+	recent := filepath.Join(dir, "traci-job-recent.json")
+	for path, modified := range map[string]time.Time{stale: now.Add(-jobStateMaxAge - time.Hour), recent: now.Add(-time.Hour)} {
+		assert.Nil(t, os.WriteFile(path, []byte(`{"started":"2024-01-02T03:04:05Z"}`), 0o644))
+		assert.Nil(t, os.Chtimes(path, modified, modified))
+	}
+
+	_, err := recordJobState(filepath.Join(dir, "traci-job-new.json"), "", now)
+	assert.Nil(t, err)
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, recent)
+	assert.FileExists(t, filepath.Join(dir, "traci-job-new.json"))
+}
+
+func TestJobEndCmd(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_PIPELINE_ID", "100")
+	t.Setenv("CI_JOB_ID", "7")
+	t.Setenv("CI_JOB_NAME", "build")
+	t.Setenv("TRACEPARENT", "")
+	t.Setenv("TRACI_TRACE_BOUNDARY", "pipeline")
+	spoolDir := t.TempDir()
+	t.Setenv("TRACI_SPOOL_DIR", spoolDir)
+
+	// The exported context of the job is the span emitted by job end
+	stdout, _, errCode := execute(t, rootCmd, "export-context", "--format", "dotenv")
+	assert.Nil(t, errCode.Err)
+	jobSpanContext := tracing.ParseTraceParent(stdout[len("TRACEPARENT="):])
+	assert.True(t, jobSpanContext.IsValid())
+	assert.FileExists(t, jobStatePath(providers.GitLabCI{}))
+
+	_, _, errCode = execute(t, rootCmd, "job", "end", "--status", "error")
+	assert.Nil(t, errCode.Err)
+	assert.NoFileExists(t, jobStatePath(providers.GitLabCI{}))
+
+	records, err := spool.Read(spoolDir)
+	assert.Nil(t, err)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "build", records[0].Name)
+		assert.Equal(t, jobSpanContext.TraceID().String(), records[0].TraceID)
+		assert.Equal(t, jobSpanContext.SpanID().String(), records[0].SpanID)
+		assert.Equal(t, "", records[0].ParentSpanID)
+		assert.Equal(t, spool.StatusCodeError, records[0].Status.Code)
+	}
+}
+
+func TestJobEndCmdOutsideCI(t *testing.T) {
+	for _, key := range []string{"GITLAB_CI", "CIRCLECI", "TRAVIS", "GITHUB_ACTION", "BITBUCKET_BUILD_NUMBER"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("TRACI_SESSION_ID", "local")
+
+	_, _, errCode := execute(t, rootCmd, "job", "end")
+	assert.NotNil(t, errCode.Err)
+}
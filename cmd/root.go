@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/spf13/viper"
 	"io/fs"
+	"log"
 	"os"
 	"os/exec"
 	"strings"
@@ -66,4 +67,24 @@ func (e *ErrorCode) Error() string {
 func init() {
 	viper.SetEnvPrefix("traci")
 	viper.AutomaticEnv()
+
+	cobra.OnInitialize(initConfig)
+}
+
+// initConfig reads the config file set in TRACI_CONFIG_FILE, or .traci.yaml in the working directory if it exists.
+// Environment variables and flags take precedence over the config file.
+func initConfig() {
+	if configFile := os.Getenv("TRACI_CONFIG_FILE"); configFile != "" {
+		viper.SetConfigFile(configFile)
+	} else {
+		viper.SetConfigName(".traci")
+		viper.AddConfigPath(".")
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			log.Fatalf("unable to read config file, %v", err)
+		}
+	}
 }
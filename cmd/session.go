@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nextrevision/traci/config"
+	"github.com/nextrevision/traci/session"
+	"github.com/spf13/cobra"
+	"text/tabwriter"
+	"time"
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "group local runs of traci outside of CI in the same trace",
+}
+
+var sessionStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "start a local session and print its ID",
+	Long: `start a local session, replacing any active session, and print its ID. Outside of CI, traci exec uses the ID of
+the active session as the pipeline ID, so consecutive runs share a trace until the session ends or is idle for longer
+than its idle timeout. Setting TRACI_SESSION_ID to the printed ID takes precedence over the session file and never
+times out.
+
+Examples:
+
+traci session start && make ci
+
+export TRACI_SESSION_ID=$(traci session start --idle-timeout 0)`,
+	Args: cobra.NoArgs,
+	RunE: runSessionStart,
+}
+
+var sessionShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "print the active local session",
+	Args:  cobra.NoArgs,
+	RunE:  runSessionShow,
+}
+
+var sessionEndCmd = &cobra.Command{
+	Use:   "end",
+	Short: "end the active local session",
+	Args:  cobra.NoArgs,
+	RunE:  runSessionEnd,
+}
+
+func init() {
+	sessionStartCmd.Flags().Duration("idle-timeout", session.DefaultIdleTimeout, "time without runs after which the session expires, or 0 to never expire")
+
+	sessionCmd.AddCommand(sessionStartCmd)
+	sessionCmd.AddCommand(sessionShowCmd)
+	sessionCmd.AddCommand(sessionEndCmd)
+	rootCmd.AddCommand(sessionCmd)
+}
+
+// sessionFile returns the configured session file, falling back to the one in the user's cache directory.
+func sessionFile(traciConfig *config.Config) (string, error) {
+	if traciConfig.SessionFile != "" {
+		return traciConfig.SessionFile, nil
+	}
+	return session.DefaultPath()
+}
+
+func runSessionStart(cmd *cobra.Command, args []string) error {
+	path, err := sessionFile(getConfig())
+	if err != nil {
+		return err
+	}
+	idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
+
+	s, err := session.Start(path, idleTimeout, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), s.ID)
+	return nil
+}
+
+func runSessionShow(cmd *cobra.Command, args []string) error {
+	path, err := sessionFile(getConfig())
+	if err != nil {
+		return err
+	}
+
+	s, err := session.Read(path, time.Now())
+	if errors.Is(err, session.ErrNoSession) {
+		fmt.Fprintln(cmd.OutOrStdout(), "no active session")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "id:\t%s\n", s.ID)
+	fmt.Fprintf(w, "started:\t%s\n", s.Started.Format(time.RFC3339))
+	fmt.Fprintf(w, "last used:\t%s\n", s.LastUsed.Format(time.RFC3339))
+	fmt.Fprintf(w, "idle timeout:\t%s\n", s.IdleTimeout)
+	fmt.Fprintf(w, "file:\t%s\n", path)
+	return w.Flush()
+}
+
+func runSessionEnd(cmd *cobra.Command, args []string) error {
+	path, err := sessionFile(getConfig())
+	if err != nil {
+		return err
+	}
+	return session.End(path)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/nextrevision/traci/session"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionResumedBySpanCommands(t *testing.T) {
+	for _, key := range []string{"GITLAB_CI", "CIRCLECI", "TRAVIS", "GITHUB_ACTION", "BITBUCKET_BUILD_NUMBER", "TF_BUILD", "JENKINS_URL"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("TRACI_SESSION_ID", "")
+	t.Setenv("TRACI_SPOOL_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "session.json")
+	t.Setenv("TRACI_SESSION_FILE", path)
+
+	started := time.Now().Add(-time.Minute)
+	_, err := session.Start(path, time.Hour, started)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantResumed bool
+	}{
+		{"Case for detect", []string{"detect"}, false},
+		{"Case for export-context", []string{"export-context"}, false},
+		{"Case for exec", []string{"exec", "true"}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			before, err := session.Read(path, time.Now())
+			assert.Nil(t, err)
+
+			_, _, errCode := execute(t, rootCmd, tc.args...)
+			assert.Nil(t, errCode.Err)
+
+			after, err := session.Read(path, time.Now())
+			assert.Nil(t, err)
+			assert.Equal(t, before.ID, after.ID)
+			assert.Equal(t, tc.wantResumed, after.LastUsed.After(before.LastUsed))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nextrevision/traci/config"
+	"github.com/nextrevision/traci/providers"
+	"github.com/nextrevision/traci/tracing"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
+	"io/fs"
+	"time"
+)
+
+var stageCmd = &cobra.Command{
+	Use:   "stage",
+	Short: "record the span of the current CI stage",
+}
+
+var stageEndCmd = &cobra.Command{
+	Use:   "end",
+	Short: "emit the span of the current CI stage",
+	Long: `emit the stage span, which the job spans of the stage are parented under when TRACI_STAGE_SPANS is set. Run it in
+the last job of the stage, which needs every other job of the stage, after they finished. The span starts at the time
+given by --started, or else when the current job started, and ends now.
+
+Examples:
+
+TRACI_STAGE_SPANS=true traci stage end --started "$CI_PIPELINE_CREATED_AT"`,
+	Args: cobra.NoArgs,
+	RunE: runStageEnd,
+}
+
+func init() {
+	stageEndCmd.Flags().Var(newSpanStatusValue(), "status", "status of the stage span, unset, ok or error")
+	stageEndCmd.Flags().String("started", "", "RFC 3339 time the stage started at, defaults to the start of the current job")
+
+	// Don't print the usage for an unknown provider or outside of CI
+	stageEndCmd.SilenceUsage = true
+	// Handle errors ourselves
+	stageEndCmd.SilenceErrors = true
+
+	stageCmd.AddCommand(stageEndCmd)
+	rootCmd.AddCommand(stageCmd)
+}
+
+// newStageSpanContext returns the span context of the stage span, whose span ID is derived from the stage ID. It
+// returns false if the CI provider has no stage between the pipeline and the job.
+func newStageSpanContext(traciConfig *config.Config, ciProvider providers.Provider) (trace.SpanContext, bool) {
+	stageID := ciProvider.GetStageID()
+	if stageID == ciProvider.GetPipelineID() || stageID == ciProvider.GetJobID() {
+		return trace.SpanContext{}, false
+	}
+	traceID := traceIDString(traciConfig, ciProvider.GetPipelineID(), stageID, ciProvider.GetJobID())
+	return tracing.NewDeterministicSpanContext(traceID, stageID), true
+}
+
+func runStageEnd(cmd *cobra.Command, args []string) error {
+	traciConfig := getConfig()
+	ciProvider, err := detectProvider(traciConfig)
+	if err != nil {
+		return err
+	}
+	stageSpanContext, ok := newStageSpanContext(traciConfig, ciProvider)
+	if !ok {
+		return fmt.Errorf("%s has no stages with their own spans", ciProvider.GetCIName())
+	}
+
+	var started time.Time
+	if value, _ := cmd.Flags().GetString("started"); value != "" {
+		var err error
+		if started, err = time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("invalid value '%s' for --started: %w", value, err)
+		}
+	} else if state, err := readJobState(jobStatePath(ciProvider)); err == nil {
+		started = state.Started
+	} else if errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(cmd.ErrOrStderr(), "WARN no start of the stage was given, the stage span starts now")
+		started = time.Now()
+	} else {
+		return err
+	}
+
+	status := config.SpanStatus(cmd.Flags().Lookup("status").Value.String())
+	emitSpan(cmd.Context(), traciConfig, ciProvider, stageSpanContext, trace.SpanContext{}, ciProvider.GetStageName(), started, status)
+	return nil
+}
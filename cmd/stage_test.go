@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/nextrevision/traci/spool"
+	"github.com/nextrevision/traci/tracing"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStageEndCmd(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_PIPELINE_ID", "100")
+	t.Setenv("CI_JOB_STAGE", "test")
+	t.Setenv("CI_JOB_ID", "7")
+	t.Setenv("CI_JOB_NAME", "unit")
+	t.Setenv("TRACEPARENT", "")
+	t.Setenv("TRACI_TRACE_BOUNDARY", "pipeline")
+	t.Setenv("TRACI_STAGE_SPANS", "true")
+	spoolDir := t.TempDir()
+	t.Setenv("TRACI_SPOOL_DIR", spoolDir)
+
+	_, _, errCode := execute(t, rootCmd, "job", "end")
+	assert.Nil(t, errCode.Err)
+	_, _, errCode = execute(t, rootCmd, "stage", "end", "--started", "2024-01-02T03:04:05Z")
+	assert.Nil(t, errCode.Err)
+
+	records, err := spool.Read(spoolDir)
+	assert.Nil(t, err)
+	stageSpanContext := tracing.NewDeterministicSpanContext("100", "100-test")
+	spans := map[string]spool.Record{}
+	for _, r := range records {
+		spans[r.Name] = r
+	}
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, stageSpanContext.SpanID().String(), spans["unit"].ParentSpanID)
+		assert.Equal(t, stageSpanContext.TraceID().String(), spans["test"].TraceID)
+		assert.Equal(t, stageSpanContext.SpanID().String(), spans["test"].SpanID)
+		assert.Equal(t, "", spans["test"].ParentSpanID)
+		assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(spans["test"].StartTime()))
+	}
+}
+
+func TestStageEndCmdWithoutStages(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_PIPELINE_ID", "100")
+	t.Setenv("CI_JOB_STAGE", "")
+	t.Setenv("CI_JOB_ID", "7")
+
+	_, _, errCode := execute(t, rootCmd, "stage", "end")
+	assert.NotNil(t, errCode.Err)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/nextrevision/traci/summary"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+const githubStepSummaryKey = "GITHUB_STEP_SUMMARY"
+
+var summaryCmd = &cobra.Command{
+	Use:   "summary [path...]",
+	Short: "render a Markdown timing summary of the spans recorded in the job",
+	Long: `render a Markdown table of the commands recorded in the job with their duration, exit code and share of the job
+time, followed by the slowest steps. Paths can be spool directories or OTLP JSON files and default to the configured
+spool directory.
+
+Examples:
+
+TRACI_SPOOL_DIR=.traci traci exec make build; traci summary
+
+traci summary --github-step-summary
+
+traci summary -o summary.md`,
+	RunE: runSummary,
+}
+
+func init() {
+	summaryCmd.Flags().Bool("github-step-summary", false, "append the summary to the file in $GITHUB_STEP_SUMMARY")
+	summaryCmd.Flags().StringP("output", "o", "-", "file to write the summary to, or - for stdout")
+	summaryCmd.Flags().Int("top", summary.DefaultTop, "number of slowest steps to list")
+
+	rootCmd.AddCommand(summaryCmd)
+}
+
+func runSummary(cmd *cobra.Command, args []string) error {
+	top, _ := cmd.Flags().GetInt("top")
+	if top < 0 {
+		return fmt.Errorf("invalid value '%d' for --top, the number of steps cannot be negative", top)
+	}
+
+	records, err := readSpool(args)
+	if err != nil {
+		return err
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+
+	var w io.Writer = cmd.OutOrStdout()
+	if stepSummary, _ := cmd.Flags().GetBool("github-step-summary"); stepSummary {
+		path := os.Getenv(githubStepSummaryKey)
+		if path == "" {
+			return fmt.Errorf("%s is not set", githubStepSummaryKey)
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	} else if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return summary.Write(w, records, top)
+}
@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nextrevision/traci/config"
+	"github.com/nextrevision/traci/git"
+	"github.com/nextrevision/traci/providers"
+	"github.com/nextrevision/traci/session"
+	"github.com/nextrevision/traci/spool"
+	"github.com/nextrevision/traci/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Attributes of related pipelines.
+const (
+	// previousAttemptKey holds the attempt number of the previous attempt
+	previousAttemptKey = attribute.Key("traci.pipeline.attempt")
+	// upstreamPipelineIDKey holds the pipeline ID of the upstream pipeline
+	upstreamPipelineIDKey = attribute.Key("traci.upstream.pipeline.id")
+	// upstreamTraceIDKey holds the trace ID derived from the pipeline ID of the upstream pipeline
+	upstreamTraceIDKey = attribute.Key("traci.upstream.trace.id")
+)
+
+// newServiceName returns the configured service name, falling back to the one of the CI provider.
+func newServiceName(traciConfig *config.Config, ciProvider providers.Provider) string {
+	if traciConfig.ServiceName != "" {
+		return traciConfig.ServiceName
+	}
+	return ciProvider.GetServiceName()
+}
+
+// newSpanName returns the configured span name, falling back to the CI provider's span name suffixed with command.
+func newSpanName(traciConfig *config.Config, ciProvider providers.Provider, command string) string {
+	if traciConfig.SpanName != "" {
+		return traciConfig.SpanName
+	}
+	return fmt.Sprintf("%s:%s", ciProvider.GetSpanName(), command)
+}
+
+// newResource returns the resource of spans. It is identified by the service, CI provider, repository and runner,
+// while the CI run and job, described by the CI/CD semantic convention attributes and unless disabled the provider's
+// own legacy attributes, and invocationAttributes describe a single invocation of traci. These are only put on the
+// resource in the resource attribute mode, see newTraceProviderOptions.
+func newResource(ctx context.Context, traciConfig *config.Config, ciProvider providers.Provider, invocationAttributes []attribute.KeyValue) *resource.Resource {
+	resourceAttributes := newStableAttributes(traciConfig, ciProvider)
+	perProcess := traciConfig.AttributeMode != config.AttributeModeSpan
+	if perProcess {
+		resourceAttributes = append(resourceAttributes, newInvocationAttributes(traciConfig, ciProvider, invocationAttributes)...)
+	}
+	return tracing.NewResource(ctx, newServiceName(traciConfig, ciProvider), resourceAttributes, perProcess)
+}
+
+// stableSemconvKeys are the semantic convention attributes which are shared by every run of the pipeline.
+var stableSemconvKeys = []string{
+	providers.CICDPipelineNameKey,
+	providers.VCSRepositoryNameKey,
+	providers.VCSRepositoryURLFullKey,
+}
+
+// Attributes of the local git checkout without semantic conventions.
+const (
+	gitDirtyKey             = attribute.Key("traci.git.dirty")
+	gitCommitAuthorNameKey  = attribute.Key("traci.git.commit.author.name")
+	gitCommitAuthorEmailKey = attribute.Key("traci.git.commit.author.email")
+	gitCommitTimeKey        = attribute.Key("traci.git.commit.time")
+)
+
+// localGitMetadata returns the metadata of the git repository of the working directory, or nil outside a repository.
+var localGitMetadata = sync.OnceValue(func() *git.Metadata {
+	metadata, err := git.Read(".")
+	if err != nil {
+		slog.Debug(fmt.Sprintf("could not read git metadata: %v", err))
+	}
+	return metadata
+})
+
+// vcsSemconvKeys are the VCS attributes which are read from the local git repository when the CI provider doesn't
+// expose them.
+var vcsSemconvKeys = []string{
+	providers.VCSRefHeadRevisionKey,
+	providers.VCSRefHeadNameKey,
+	providers.VCSRepositoryURLFullKey,
+	providers.VCSRepositoryNameKey,
+}
+
+// newLocalGitMetadata returns the metadata of the local git repository if the CI provider leaves any of the VCS
+// attributes in semconvAttributes empty, such as outside of CI, or else nil so the repository is never read in CI.
+func newLocalGitMetadata(semconvAttributes map[string]string) *git.Metadata {
+	for _, key := range vcsSemconvKeys {
+		if _, ok := semconvAttributes[key]; !ok {
+			return localGitMetadata()
+		}
+	}
+	return nil
+}
+
+// newSemconvAttributes returns the semantic convention attributes of the CI provider. The VCS attributes it doesn't
+// expose, such as outside of CI, are read from the local git repository.
+func newSemconvAttributes(ciProvider providers.Provider) map[string]string {
+	attributes := providers.SemconvAttributes(ciProvider)
+	metadata := newLocalGitMetadata(attributes)
+	if metadata == nil {
+		return attributes
+	}
+
+	local := map[string]string{
+		providers.VCSRefHeadRevisionKey:   metadata.CommitSHA,
+		providers.VCSRepositoryURLFullKey: metadata.RemoteURL,
+		providers.VCSRepositoryNameKey:    git.RepositoryName(metadata.RemoteURL),
+	}
+	for key, value := range local {
+		if _, ok := attributes[key]; !ok && value != "" {
+			attributes[key] = value
+		}
+	}
+	if _, ok := attributes[providers.VCSRefHeadNameKey]; !ok && metadata.Branch != "" {
+		attributes[providers.VCSRefHeadNameKey] = metadata.Branch
+		attributes[providers.VCSRefHeadTypeKey] = "branch"
+	}
+	return attributes
+}
+
+// newGitAttributes returns the attributes of the local git checkout which have no semantic conventions, when the local
+// git repository is read for the VCS attributes the CI provider doesn't expose.
+func newGitAttributes(ciProvider providers.Provider) []attribute.KeyValue {
+	metadata := newLocalGitMetadata(providers.SemconvAttributes(ciProvider))
+	if metadata == nil {
+		return nil
+	}
+
+	attributes := []attribute.KeyValue{gitDirtyKey.Bool(metadata.Dirty)}
+	if metadata.Author != "" {
+		attributes = append(attributes, gitCommitAuthorNameKey.String(metadata.Author))
+		attributes = append(attributes, gitCommitAuthorEmailKey.String(metadata.AuthorEmail))
+		attributes = append(attributes, gitCommitTimeKey.String(metadata.CommitTime.Format(time.RFC3339)))
+	}
+	return attributes
+}
+
+// newStableAttributes returns the attributes shared by every invocation of traci in the repository and pipeline.
+func newStableAttributes(traciConfig *config.Config, ciProvider providers.Provider) []attribute.KeyValue {
+	semconvAttributes := newSemconvAttributes(ciProvider)
+
+	var attributes []attribute.KeyValue
+	for _, key := range stableSemconvKeys {
+		if value, ok := semconvAttributes[key]; ok {
+			attributes = append(attributes, attribute.String(key, value))
+		}
+	}
+	attributes = append(attributes, attribute.String("traci.ci.provider", ciProvider.GetCIName()))
+	attributes = append(attributes, attribute.String("traci.boundary", traciConfig.TraceBoundary))
+	attributes = append(attributes, attribute.String("traci.version", rootCmd.Version))
+	return attributes
+}
+
+// newInvocationAttributes returns the attributes describing the CI run and job of this invocation of traci, followed
+// by invocationAttributes.
+func newInvocationAttributes(traciConfig *config.Config, ciProvider providers.Provider, invocationAttributes []attribute.KeyValue) []attribute.KeyValue {
+	semconvAttributes := newSemconvAttributes(ciProvider)
+	for _, key := range stableSemconvKeys {
+		delete(semconvAttributes, key)
+	}
+
+	var attributes []attribute.KeyValue
+	if traciConfig.LegacyAttributes {
+		attributes = append(attributes, tracing.AttributeMapToKeyValue(ciProvider.GetAttributes())...)
+	}
+	attributes = append(attributes, tracing.AttributeMapToKeyValue(semconvAttributes)...)
+	attributes = append(attributes, newGitAttributes(ciProvider)...)
+	attributes = append(attributes, newUpstreamAttributes(traciConfig, ciProvider)...)
+	return append(attributes, invocationAttributes...)
+}
+
+// detectProvider returns the configured CI provider, or else the one detected from the environment. Outside of CI, runs
+// share the pipeline ID of the session ID if one is configured, or else of the active session started with
+// `traci session start`. An error is returned if the configured provider is unknown.
+func detectProvider(traciConfig *config.Config) (providers.Provider, error) {
+	return newProvider(traciConfig, session.Read)
+}
+
+// resumeProvider returns the CI provider like detectProvider, extending the idle timeout of the active session. It is
+// used by the commands emitting spans, so only runs adding to the session keep it alive.
+func resumeProvider(traciConfig *config.Config) (providers.Provider, error) {
+	return newProvider(traciConfig, session.Resume)
+}
+
+// newProvider returns the CI provider of detectProvider, reading the active session with readSession.
+func newProvider(traciConfig *config.Config, readSession func(path string, now time.Time) (*session.Session, error)) (providers.Provider, error) {
+	ciProvider, err := selectProvider(traciConfig)
+	if err != nil {
+		return nil, err
+	}
+	defaultProvider, ok := ciProvider.(providers.DefaultProvider)
+	if !ok {
+		return ciProvider, nil
+	}
+
+	if traciConfig.SessionID != "" {
+		defaultProvider.SessionID = traciConfig.SessionID
+		return defaultProvider, nil
+	}
+
+	path, err := sessionFile(traciConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN could not find session file: %v\n", err)
+		return defaultProvider, nil
+	}
+	s, err := readSession(path, time.Now())
+	if err == nil {
+		defaultProvider.SessionID = s.ID
+	} else if !errors.Is(err, session.ErrNoSession) {
+		fmt.Fprintf(os.Stderr, "WARN could not read session: %v\n", err)
+	}
+	return defaultProvider, nil
+}
+
+// selectProvider returns the provider named by the configuration, or else the one detected from the environment.
+func selectProvider(traciConfig *config.Config) (providers.Provider, error) {
+	if traciConfig.Provider != "" {
+		return providers.ProviderByName(traciConfig.Provider)
+	}
+	return providers.DetectProvider(), nil
+}
+
+// newTraceContext returns a context carrying the parent of new spans. In CI, spans are parented under the job span,
+// which is emitted by `traci job end`. Its trace ID is derived from the CI provider according to the trace boundary and
+// its span ID from the job ID, unless the first invocation of traci in the job found a trace context in the
+// environment, such as the TRACEPARENT exported by an upstream job, which the job span is then parented under. Other
+// trace contexts found in the environment by the configured propagators, such as the one traci exec passes to its
+// command, and any found outside of CI are used as the parent instead. Baggage from the environment and the configured
+// baggage is carried in either case.
+func newTraceContext(ctx context.Context, traciConfig *config.Config, ciProvider providers.Provider) context.Context {
+	traceCtx, err := tracing.NewContextFromEnv(ctx, os.Environ())
+	if _, local := ciProvider.(providers.DefaultProvider); local {
+		if err != nil {
+			traceID := traceIDString(traciConfig, ciProvider.GetPipelineID(), ciProvider.GetStageID(), ciProvider.GetJobID())
+			jobCtx := tracing.NewContextFromDeterministicString(traceID, ciProvider.GetJobID())
+			traceCtx = baggage.ContextWithBaggage(jobCtx, baggage.FromContext(traceCtx))
+		}
+	} else {
+		var envParent string
+		if err == nil {
+			envParent = tracing.GenTraceParentString(trace.SpanContextFromContext(traceCtx))
+		}
+		state, stateErr := recordJobState(jobStatePath(ciProvider), envParent, time.Now())
+		if stateErr != nil {
+			fmt.Fprintf(os.Stderr, "WARN could not record the start of the job: %v\n", stateErr)
+			state.Parent = envParent
+		}
+		if err != nil || envParent == state.Parent {
+			jobSpanContext := newJobSpanContext(traciConfig, ciProvider, tracing.ParseTraceParent(state.Parent))
+			jobCtx := trace.ContextWithRemoteSpanContext(context.Background(), jobSpanContext)
+			traceCtx = baggage.ContextWithBaggage(jobCtx, baggage.FromContext(traceCtx))
+		}
+	}
+
+	if baggageCtx, err := tracing.ContextWithBaggage(traceCtx, traciConfig.Baggage); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR could not set baggage: %v\n", err)
+	} else {
+		traceCtx = baggageCtx
+	}
+	return traceCtx
+}
+
+// traceIDString returns the string the deterministic trace ID is derived from according to the trace boundary.
+func traceIDString(traciConfig *config.Config, pipelineID, stageID, jobID string) string {
+	switch traciConfig.TraceBoundary {
+	case string(config.TraceBoundaryJob):
+		return jobID
+	case string(config.TraceBoundaryStage):
+		return stageID
+	default:
+		return pipelineID
+	}
+}
+
+// newSpanLinks returns the links of the spans traci starts for a command. When the pipeline is re-run, a link to the job
+// span of the previous attempt lets the trace of each attempt lead to the one it retried. When the pipeline was
+// triggered by another pipeline and the trace context of the upstream job is configured, a link to its span connects
+// the two traces. No links are added when the trace context is taken from the environment.
+func newSpanLinks(traciConfig *config.Config, ciProvider providers.Provider) []trace.Link {
+	if _, err := tracing.NewContextFromEnv(context.Background(), os.Environ()); err == nil {
+		return nil
+	}
+
+	var links []trace.Link
+	if attempt := ciProvider.GetAttempt(); attempt > 1 {
+		// Stage and job IDs are prefixed by the pipeline ID, which is swapped for the one of the previous attempt
+		pipelineID := ciProvider.GetPipelineID()
+		previousPipelineID := providers.PipelineIDForAttempt(ciProvider, attempt-1)
+		previousStageID := previousPipelineID + strings.TrimPrefix(ciProvider.GetStageID(), pipelineID)
+		previousJobID := previousPipelineID + strings.TrimPrefix(ciProvider.GetJobID(), pipelineID)
+
+		links = append(links, trace.Link{
+			SpanContext: tracing.NewDeterministicSpanContext(traceIDString(traciConfig, previousPipelineID, previousStageID, previousJobID), previousJobID),
+			Attributes: []attribute.KeyValue{
+				previousAttemptKey.Int(attempt - 1),
+			},
+		})
+	}
+
+	if upstream := tracing.ParseTraceParent(traciConfig.UpstreamTraceParent); upstream.IsValid() {
+		var attributes []attribute.KeyValue
+		if upstreamPipelineID := newUpstreamPipelineID(traciConfig, ciProvider); upstreamPipelineID != "" {
+			attributes = append(attributes, upstreamPipelineIDKey.String(upstreamPipelineID))
+		}
+		links = append(links, trace.Link{SpanContext: upstream, Attributes: attributes})
+	}
+	return links
+}
+
+// newUpstreamPipelineID returns the configured ID of the upstream pipeline, falling back to the one of the CI provider.
+func newUpstreamPipelineID(traciConfig *config.Config, ciProvider providers.Provider) string {
+	if traciConfig.UpstreamPipelineID != "" {
+		return traciConfig.UpstreamPipelineID
+	}
+	return ciProvider.GetUpstreamPipelineID()
+}
+
+// newUpstreamAttributes returns the attributes identifying the pipeline which triggered this one and, with the
+// pipeline trace boundary, the trace ID derived from it. The stage and job of the upstream trigger are unknown, so the
+// trace ID of the other boundaries can't be derived.
+func newUpstreamAttributes(traciConfig *config.Config, ciProvider providers.Provider) []attribute.KeyValue {
+	upstreamPipelineID := newUpstreamPipelineID(traciConfig, ciProvider)
+	if upstreamPipelineID == "" {
+		return nil
+	}
+
+	attributes := []attribute.KeyValue{upstreamPipelineIDKey.String(upstreamPipelineID)}
+	boundary := config.TraceBoundary(traciConfig.TraceBoundary)
+	if boundary != config.TraceBoundaryStage && boundary != config.TraceBoundaryJob {
+		traceID := tracing.NewDeterministicSpanContext(upstreamPipelineID, upstreamPipelineID).TraceID()
+		attributes = append(attributes, upstreamTraceIDKey.String(traceID.String()))
+	}
+	return attributes
+}
+
+// newTraceProviderOptions returns the additional options of the TracerProvider, which records spans in the spool
+// directory when one is configured. In the span attribute mode, the attributes describing this invocation of traci,
+// followed by invocationAttributes, are set on its root spans.
+func newTraceProviderOptions(traciConfig *config.Config, ciProvider providers.Provider, invocationAttributes []attribute.KeyValue) []sdktrace.TracerProviderOption {
+	var opts []sdktrace.TracerProviderOption
+	if traciConfig.AttributeMode == config.AttributeModeSpan {
+		attributes := newInvocationAttributes(traciConfig, ciProvider, invocationAttributes)
+		opts = append(opts, sdktrace.WithSpanProcessor(tracing.NewRootAttributesProcessor(attributes)))
+	}
+	if traciConfig.SpoolDir != "" {
+		exporter, err := spool.NewExporter(traciConfig.SpoolDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR could not create spool exporter: %v\n", err)
+		} else {
+			opts = append(opts, sdktrace.WithBatcher(exporter))
+		}
+	}
+	return opts
+}
+
+// shutdownTraceProvider ends the given spans, flushes them and shuts down the TraceProvider. Each flush operation is
+// limited to flushTimeout and the whole shutdown gives up after timeout so a slow or unreachable collector never blocks
+// the caller.
+func shutdownTraceProvider(ctx context.Context, traceProvider *sdktrace.TracerProvider, flushTimeout, timeout time.Duration, spans ...trace.Span) {
+	sent := make(chan bool, 1)
+	go func() {
+		for _, span := range spans {
+			span.End()
+		}
+
+		ctxTimeout, cancel := context.WithTimeout(ctx, flushTimeout)
+		defer cancel()
+
+		err := traceProvider.ForceFlush(ctxTimeout)
+		if err != nil {
+			slog.Debug(err.Error())
+		}
+		err = traceProvider.Shutdown(ctxTimeout)
+		if err != nil {
+			slog.Debug(err.Error())
+		}
+		sent <- true
+	}()
+
+	select {
+	case <-sent:
+	case <-time.After(timeout):
+	}
+}
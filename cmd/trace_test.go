@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"github.com/nextrevision/traci/config"
+	"github.com/nextrevision/traci/providers"
+	"github.com/nextrevision/traci/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSpanLinks(t *testing.T) {
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(eventPath, []byte(`{"workflow_run":{"id":41,"run_number":3,"run_attempt":2}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	type link struct {
+		traceParent string
+		attributes  []attribute.KeyValue
+	}
+	tests := []struct {
+		name        string
+		traciConfig config.Config
+		attempt     string
+		eventName   string
+		traceParent string
+		want        []link
+	}{
+		{
+			name:        "Case for first attempt",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryPipeline)},
+			attempt:     "1",
+		},
+		{
+			name:        "Case for trace context from the environment",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryPipeline), UpstreamPipelineID: "41-3-2"},
+			attempt:     "2",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:        "Case for re-run with pipeline boundary",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryPipeline)},
+			attempt:     "2",
+			want: []link{{
+				traceParent: tracing.GenTraceParentString(tracing.NewDeterministicSpanContext("42-7-1", "42-7-1-build")),
+				attributes:  []attribute.KeyValue{previousAttemptKey.Int(1)},
+			}},
+		},
+		{
+			name:        "Case for re-run with job boundary",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryJob)},
+			attempt:     "3",
+			want: []link{{
+				traceParent: tracing.GenTraceParentString(tracing.NewDeterministicSpanContext("42-7-2-build", "42-7-2-build")),
+				attributes:  []attribute.KeyValue{previousAttemptKey.Int(2)},
+			}},
+		},
+		{
+			name:        "Case for workflow_run trigger without upstream job",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryPipeline)},
+			attempt:     "1",
+			eventName:   "workflow_run",
+		},
+		{
+			name: "Case for workflow_run trigger with upstream job",
+			traciConfig: config.Config{
+				TraceBoundary:       string(config.TraceBoundaryPipeline),
+				UpstreamTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			attempt:   "1",
+			eventName: "workflow_run",
+			want: []link{{
+				traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				attributes:  []attribute.KeyValue{upstreamPipelineIDKey.String("41-3-2")},
+			}},
+		},
+		{
+			name: "Case for upstream pipeline ID override on re-run",
+			traciConfig: config.Config{
+				TraceBoundary:       string(config.TraceBoundaryPipeline),
+				UpstreamPipelineID:  "1234",
+				UpstreamTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			attempt:   "2",
+			eventName: "workflow_run",
+			want: []link{{
+				traceParent: tracing.GenTraceParentString(tracing.NewDeterministicSpanContext("42-7-1", "42-7-1-build")),
+				attributes:  []attribute.KeyValue{previousAttemptKey.Int(1)},
+			}, {
+				traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				attributes:  []attribute.KeyValue{upstreamPipelineIDKey.String("1234")},
+			}},
+		},
+		{
+			name:        "Case for invalid upstream traceparent",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryPipeline), UpstreamTraceParent: "invalid"},
+			attempt:     "1",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GITHUB_RUN_ID", "42")
+			t.Setenv("GITHUB_RUN_NUMBER", "7")
+			t.Setenv("GITHUB_RUN_ATTEMPT", tc.attempt)
+			t.Setenv("GITHUB_JOB", "build")
+			t.Setenv("GITHUB_EVENT_NAME", tc.eventName)
+			t.Setenv("GITHUB_EVENT_PATH", eventPath)
+			t.Setenv("TRACEPARENT", tc.traceParent)
+
+			var got []link
+			for _, l := range newSpanLinks(&tc.traciConfig, providers.GitHubActions{}) {
+				got = append(got, link{traceParent: tracing.GenTraceParentString(l.SpanContext), attributes: l.Attributes})
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewUpstreamAttributes(t *testing.T) {
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(eventPath, []byte(`{"workflow_run":{"id":41,"run_number":3,"run_attempt":2}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	upstreamTraceID := tracing.NewDeterministicSpanContext("41-3-2", "").TraceID().String()
+
+	tests := []struct {
+		name        string
+		traciConfig config.Config
+		eventName   string
+		want        []attribute.KeyValue
+	}{
+		{
+			name:        "Case for no upstream pipeline",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryPipeline)},
+		},
+		{
+			name:        "Case for workflow_run trigger",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryPipeline)},
+			eventName:   "workflow_run",
+			want:        []attribute.KeyValue{upstreamPipelineIDKey.String("41-3-2"), upstreamTraceIDKey.String(upstreamTraceID)},
+		},
+		{
+			name:        "Case for upstream pipeline with job boundary",
+			traciConfig: config.Config{TraceBoundary: string(config.TraceBoundaryJob), UpstreamPipelineID: "1234"},
+			want:        []attribute.KeyValue{upstreamPipelineIDKey.String("1234")},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GITHUB_RUN_ID", "42")
+			t.Setenv("GITHUB_RUN_NUMBER", "7")
+			t.Setenv("GITHUB_RUN_ATTEMPT", "1")
+			t.Setenv("GITHUB_JOB", "build")
+			t.Setenv("GITHUB_EVENT_NAME", tc.eventName)
+			t.Setenv("GITHUB_EVENT_PATH", eventPath)
+
+			assert.Equal(t, tc.want, newUpstreamAttributes(&tc.traciConfig, providers.GitHubActions{}))
+		})
+	}
+}
+
+func TestNewResourceAttributeMode(t *testing.T) {
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_PIPELINE_ID", "100")
+	t.Setenv("CI_JOB_ID", "7")
+	t.Setenv("CI_PROJECT_NAME", "traci")
+
+	invocationKeys := []attribute.Key{"cicd.pipeline.task.run.id", "gitlab.job.id", "process.executable.name", "process.pid"}
+	tests := []struct {
+		name           string
+		mode           config.AttributeMode
+		wantInvocation bool
+		wantProcessors int
+	}{
+		{
+			name:           "Case for resource mode",
+			mode:           config.AttributeModeResource,
+			wantInvocation: true,
+			wantProcessors: 0,
+		},
+		{
+			name:           "Case for span mode",
+			mode:           config.AttributeModeSpan,
+			wantInvocation: false,
+			wantProcessors: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			traciConfig := &config.Config{AttributeMode: tc.mode, LegacyAttributes: true}
+			commandAttributes := []attribute.KeyValue{attribute.String("process.executable.name", "make")}
+
+			set := newResource(context.Background(), traciConfig, providers.GitLabCI{}, commandAttributes).Set()
+			_, ok := set.Value("vcs.repository.name")
+			assert.True(t, ok, "resource is missing vcs.repository.name")
+			for _, key := range invocationKeys {
+				_, ok := set.Value(key)
+				assert.Equal(t, tc.wantInvocation, ok, "unexpected presence of %s on the resource", key)
+			}
+
+			assert.Len(t, newTraceProviderOptions(traciConfig, providers.GitLabCI{}, commandAttributes), tc.wantProcessors)
+		})
+	}
+}
+
+func TestNewLocalGitMetadata(t *testing.T) {
+	tests := []struct {
+		name              string
+		semconvAttributes map[string]string
+		wantLocal         bool
+	}{
+		{
+			name: "Case for CI provider exposing every VCS attribute",
+			semconvAttributes: map[string]string{
+				providers.VCSRefHeadRevisionKey:   "abc123",
+				providers.VCSRefHeadNameKey:       "main",
+				providers.VCSRepositoryURLFullKey: "https://github.com/nextrevision/traci",
+				providers.VCSRepositoryNameKey:    "traci",
+			},
+			wantLocal: false,
+		},
+		{
+			name: "Case for CI provider without repository URL",
+			semconvAttributes: map[string]string{
+				providers.VCSRefHeadRevisionKey: "abc123",
+				providers.VCSRefHeadNameKey:     "main",
+			},
+			wantLocal: true,
+		},
+		{
+			name:              "Case for default provider",
+			semconvAttributes: map[string]string{},
+			wantLocal:         true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newLocalGitMetadata(tc.semconvAttributes)
+			if tc.wantLocal {
+				assert.Equal(t, localGitMetadata(), got)
+			} else {
+				assert.Nil(t, got)
+			}
+		})
+	}
+}
@@ -1,15 +1,79 @@
 package config
 
+import "time"
+
 type Config struct {
-	ServiceName    string `mapstructure:"service_name"`
-	SpanName       string `mapstructure:"span_name"`
-	TraceBoundary  string `mapstructure:"trace_boundary" default:"pipeline"`
-	TagCommandArgs bool   `mapstructure:"tag_command_args"`
+	ServiceName          string         `mapstructure:"service_name"`
+	SpanName             string         `mapstructure:"span_name"`
+	TraceBoundary        string         `mapstructure:"trace_boundary" default:"pipeline"`
+	TagCommandArgs       bool           `mapstructure:"tag_command_args"`
+	ProcessTree          bool           `mapstructure:"process_tree"`
+	ProcessTreeInterval  time.Duration  `mapstructure:"process_tree_interval" default:"100ms"`
+	ProcessTreeThreshold time.Duration  `mapstructure:"process_tree_threshold" default:"500ms"`
+	SpoolDir             string         `mapstructure:"spool_dir"`
+	HistoryDir           string         `mapstructure:"history_dir"`
+	Budget               time.Duration  `mapstructure:"budget"`
+	BudgetAction         BudgetAction   `mapstructure:"budget_action" default:"warn"`
+	Budgets              []BudgetRule   `mapstructure:"budgets"`
+	OkExitCodes          []int          `mapstructure:"ok_exit_codes"`
+	ExitCodes            []ExitCodeRule `mapstructure:"exit_codes"`
+	Baggage              []string       `mapstructure:"baggage"`
+	UpstreamPipelineID   string         `mapstructure:"upstream_pipeline_id"`
+	UpstreamTraceParent  string         `mapstructure:"upstream_traceparent"`
+	LegacyAttributes     bool           `mapstructure:"legacy_attributes" default:"true"`
+	AttributeMode        AttributeMode  `mapstructure:"attribute_mode" default:"resource"`
+	SessionID            string         `mapstructure:"session_id"`
+	SessionFile          string         `mapstructure:"session_file"`
+	Provider             string         `mapstructure:"provider"`
+	StageSpans           bool           `mapstructure:"stage_spans"`
+}
+
+// BudgetRule sets the duration budget of the commands matching Command, a pattern where * matches any text.
+type BudgetRule struct {
+	Command string        `mapstructure:"command"`
+	Budget  time.Duration `mapstructure:"budget"`
+	Action  BudgetAction  `mapstructure:"action"`
 }
 
 type TraceBoundary string
 
 const (
 	TraceBoundaryPipeline TraceBoundary = "pipeline"
+	TraceBoundaryStage    TraceBoundary = "stage"
 	TraceBoundaryJob      TraceBoundary = "job"
 )
+
+// ExitCodeRule maps an exit code of the commands matching Command, or of every command if Command is empty, to a span
+// status and description.
+type ExitCodeRule struct {
+	Code        int        `mapstructure:"code"`
+	Command     string     `mapstructure:"command"`
+	Status      SpanStatus `mapstructure:"status"`
+	Description string     `mapstructure:"description"`
+}
+
+type BudgetAction string
+
+const (
+	BudgetActionWarn BudgetAction = "warn"
+	BudgetActionFail BudgetAction = "fail"
+)
+
+type SpanStatus string
+
+const (
+	SpanStatusUnset SpanStatus = "unset"
+	SpanStatusOk    SpanStatus = "ok"
+	SpanStatusError SpanStatus = "error"
+)
+
+// AttributeMode determines where attributes describing a single invocation of traci, such as the job and command, are
+// recorded.
+type AttributeMode string
+
+const (
+	// AttributeModeResource records all attributes on the resource
+	AttributeModeResource AttributeMode = "resource"
+	// AttributeModeSpan keeps stable identity on the resource and records per-invocation attributes on root spans
+	AttributeModeSpan AttributeMode = "span"
+)
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Modes of index entries.
+const (
+	modeTypeMask = 0o170000
+	modeSymlink  = 0o120000
+	modeGitlink  = 0o160000
+)
+
+// Flags of index entries.
+const (
+	flagExtended  = 0x4000
+	flagStageMask = 0x3000
+	flagNameMask  = 0x0fff
+)
+
+// Extended flags of index entries, present from index version 3.
+const (
+	flagSkipWorktree = 0x4000
+	flagIntentToAdd  = 0x2000
+)
+
+// indexEntry is a file tracked by the index.
+type indexEntry struct {
+	path      string
+	mtimeSec  uint32
+	mtimeNsec uint32
+	mode      uint32
+	size      uint32
+	sha       string
+	flags     uint16
+	extended  uint16
+}
+
+// Dirty reports whether a tracked file of the work tree differs from the index, or the index has conflicts. Like
+// `git describe --dirty`, untracked files are not taken into account. Files whose size and modification time match
+// the index are assumed to be unchanged, the content of the others is compared with the object recorded in the index.
+func (r *Repository) Dirty() (bool, error) {
+	entries, err := r.readIndex()
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	for _, e := range entries {
+		if e.flags&flagStageMask != 0 || e.extended&flagIntentToAdd != 0 {
+			return true, nil
+		}
+		if e.extended&flagSkipWorktree != 0 || e.mode&modeTypeMask == modeGitlink {
+			continue
+		}
+
+		changed, err := r.changed(e)
+		if err != nil || changed {
+			return changed, err
+		}
+	}
+	return false, nil
+}
+
+// changed reports whether the file of e in the work tree differs from e.
+func (r *Repository) changed(e indexEntry) (bool, error) {
+	path := filepath.Join(r.WorkTree, filepath.FromSlash(e.path))
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	var content []byte
+	switch {
+	case e.mode&modeTypeMask == modeSymlink:
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return true, nil
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return false, err
+		}
+		content = []byte(target)
+	case info.Mode().IsRegular():
+		if (e.mode&0o111 != 0) != (info.Mode()&0o111 != 0) || e.size != uint32(info.Size()) {
+			return true, nil
+		}
+		mtime := info.ModTime()
+		if e.mtimeSec == uint32(mtime.Unix()) && e.mtimeNsec == uint32(mtime.Nanosecond()) {
+			return false, nil
+		}
+		if content, err = os.ReadFile(path); err != nil {
+			return false, err
+		}
+	default:
+		return true, nil
+	}
+
+	return blobSHA(content) != e.sha, nil
+}
+
+// blobSHA returns the object ID of a blob with content.
+func blobSHA(content []byte) string {
+	h := sha1.New()
+	h.Write([]byte("blob " + strconv.Itoa(len(content)) + "\x00"))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// readIndex reads the entries of a version 2, 3 or 4 index file.
+func (r *Repository) readIndex() ([]indexEntry, error) {
+	data, err := os.ReadFile(filepath.Join(r.GitDir, "index"))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 || !bytes.Equal(data[:4], []byte("DIRC")) {
+		return nil, errors.New("invalid index file")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported index version %d", version)
+	}
+	count := binary.BigEndian.Uint32(data[8:12])
+
+	errTruncated := errors.New("truncated index file")
+	entries := make([]indexEntry, 0, count)
+	pos := 12
+	var previous string
+	for i := uint32(0); i < count; i++ {
+		start := pos
+		if len(data) < pos+62 {
+			return nil, errTruncated
+		}
+		e := indexEntry{
+			mtimeSec:  binary.BigEndian.Uint32(data[pos+8:]),
+			mtimeNsec: binary.BigEndian.Uint32(data[pos+12:]),
+			mode:      binary.BigEndian.Uint32(data[pos+24:]),
+			size:      binary.BigEndian.Uint32(data[pos+36:]),
+			sha:       hex.EncodeToString(data[pos+40 : pos+60]),
+			flags:     binary.BigEndian.Uint16(data[pos+60:]),
+		}
+		pos += 62
+		if version >= 3 && e.flags&flagExtended != 0 {
+			if len(data) < pos+2 {
+				return nil, errTruncated
+			}
+			e.extended = binary.BigEndian.Uint16(data[pos:])
+			pos += 2
+		}
+
+		if version == 4 {
+			// Paths are prefix compressed, removing a number of bytes from the end of the previous path
+			strip, n := readIndexVarint(data[pos:])
+			if n == 0 || strip > len(previous) {
+				return nil, errTruncated
+			}
+			pos += n
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, errTruncated
+			}
+			e.path = previous[:len(previous)-strip] + string(data[pos:pos+end])
+			pos += end + 1
+		} else {
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, errTruncated
+			}
+			if length := int(e.flags & flagNameMask); length < flagNameMask && length != end {
+				return nil, errors.New("invalid index entry name")
+			}
+			e.path = string(data[pos : pos+end])
+			// Entries are padded with NUL bytes to a multiple of 8 bytes
+			pos = start + (pos-start+end+8)&^7
+		}
+
+		previous = e.path
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// readIndexVarint reads an offset encoded integer, returning it with the number of bytes read or 0 if data ends.
+func readIndexVarint(data []byte) (int, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	value := int(data[0] & 0x7f)
+	n := 1
+	for data[n-1]&0x80 != 0 {
+		if n == len(data) {
+			return 0, 0
+		}
+		value = ((value + 1) << 7) | int(data[n]&0x7f)
+		n++
+	}
+	return value, n
+}
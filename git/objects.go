@@ -0,0 +1,351 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of objects in pack files.
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var packTypeNames = map[byte]string{
+	objCommit: "commit",
+	objTree:   "tree",
+	objBlob:   "blob",
+	objTag:    "tag",
+}
+
+// Commit is the metadata of a commit object.
+type Commit struct {
+	Author      string
+	AuthorEmail string
+	Time        time.Time
+}
+
+// Commit returns the commit with the given SHA.
+func (r *Repository) Commit(sha string) (*Commit, error) {
+	objType, data, err := r.ReadObject(sha)
+	if err != nil {
+		return nil, err
+	}
+	if objType != "commit" {
+		return nil, fmt.Errorf("object %s is a %s, not a commit", sha, objType)
+	}
+	return parseCommit(data)
+}
+
+// parseCommit reads the author of a commit from its headers, such as `author Name <email> 1700000000 +0100`.
+func parseCommit(data []byte) (*Commit, error) {
+	headers, _, _ := bytes.Cut(data, []byte("\n\n"))
+	for _, line := range strings.Split(string(headers), "\n") {
+		author, found := strings.CutPrefix(line, "author ")
+		if !found {
+			continue
+		}
+
+		start, end := strings.LastIndex(author, "<"), strings.LastIndex(author, ">")
+		if start < 0 || end < start {
+			return nil, fmt.Errorf("invalid commit author %q", author)
+		}
+		commit := &Commit{
+			Author:      strings.TrimSpace(author[:start]),
+			AuthorEmail: author[start+1 : end],
+		}
+
+		fields := strings.Fields(author[end+1:])
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid commit author %q", author)
+		}
+		seconds, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit time %q", fields[0])
+		}
+		zone, err := time.Parse("-0700", fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit time zone %q", fields[1])
+		}
+		commit.Time = time.Unix(seconds, 0).In(zone.Location())
+		return commit, nil
+	}
+	return nil, errors.New("commit has no author")
+}
+
+// ReadObject returns the type and content of the object with the given SHA, read from its loose object file or from
+// the pack files.
+func (r *Repository) ReadObject(sha string) (string, []byte, error) {
+	id, err := hex.DecodeString(sha)
+	if err != nil || len(id) != 20 {
+		return "", nil, fmt.Errorf("invalid object ID %q", sha)
+	}
+
+	objType, data, err := r.readLooseObject(sha)
+	if !errors.Is(err, os.ErrNotExist) {
+		return objType, data, err
+	}
+
+	packs, err := filepath.Glob(filepath.Join(r.CommonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, idx := range packs {
+		offset, found, err := findPackOffset(idx, id)
+		if err != nil {
+			return "", nil, err
+		}
+		if found {
+			return r.readPackObject(strings.TrimSuffix(idx, ".idx")+".pack", offset)
+		}
+	}
+	return "", nil, fmt.Errorf("object %s not found", sha)
+}
+
+func (r *Repository) readLooseObject(sha string) (string, []byte, error) {
+	f, err := os.Open(filepath.Join(r.CommonDir, "objects", sha[:2], sha[2:]))
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Loose objects start with a `<type> <size>` header terminated by a NUL byte
+	header, content, found := bytes.Cut(data, []byte{0})
+	if !found {
+		return "", nil, fmt.Errorf("invalid object %s", sha)
+	}
+	objType, _, _ := strings.Cut(string(header), " ")
+	return objType, content, nil
+}
+
+// findPackOffset looks up id in a version 2 pack index, returning the offset of the object in the pack file.
+func findPackOffset(idx string, id []byte) (int64, bool, error) {
+	data, err := os.ReadFile(idx)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return 0, false, fmt.Errorf("unsupported pack index %s", idx)
+	}
+
+	fanout := data[8 : 8+256*4]
+	count := int(binary.BigEndian.Uint32(fanout[255*4:]))
+	var lo int
+	if id[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(fanout[(int(id[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(fanout[int(id[0])*4:]))
+
+	names := 8 + 256*4
+	crcs := names + count*20
+	offsets := crcs + count*4
+	largeOffsets := offsets + count*4
+	if len(data) < largeOffsets {
+		return 0, false, fmt.Errorf("truncated pack index %s", idx)
+	}
+
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch cmp := bytes.Compare(data[names+mid*20:names+mid*20+20], id); {
+		case cmp < 0:
+			lo = mid + 1
+		case cmp > 0:
+			hi = mid
+		default:
+			offset := binary.BigEndian.Uint32(data[offsets+mid*4:])
+			if offset&0x80000000 == 0 {
+				return int64(offset), true, nil
+			}
+			// Offsets beyond 2GiB are stored in the table of 64-bit offsets
+			i := largeOffsets + int(offset&0x7fffffff)*8
+			if len(data) < i+8 {
+				return 0, false, fmt.Errorf("truncated pack index %s", idx)
+			}
+			return int64(binary.BigEndian.Uint64(data[i:])), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// readPackObject reads the object at offset in a pack file, applying deltas to their base objects.
+func (r *Repository) readPackObject(pack string, offset int64) (string, []byte, error) {
+	f, err := os.Open(pack)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	return r.readPackEntry(f, offset)
+}
+
+func (r *Repository) readPackEntry(f *os.File, offset int64) (string, []byte, error) {
+	br := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+
+	// The entry header holds the type and the inflated size in a variable length integer
+	b, err := br.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+	entryType := (b >> 4) & 7
+	for b&0x80 != 0 {
+		if b, err = br.ReadByte(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	switch entryType {
+	case objOfsDelta:
+		// The base is at a negative offset encoded with an offset added for every continuation byte
+		b, err := br.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+		distance := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = br.ReadByte(); err != nil {
+				return "", nil, err
+			}
+			distance = ((distance + 1) << 7) | int64(b&0x7f)
+		}
+		delta, err := inflate(br)
+		if err != nil {
+			return "", nil, err
+		}
+		baseType, base, err := r.readPackEntry(f, offset-distance)
+		if err != nil {
+			return "", nil, err
+		}
+		data, err := applyDelta(base, delta)
+		return baseType, data, err
+	case objRefDelta:
+		baseID := make([]byte, 20)
+		if _, err := io.ReadFull(br, baseID); err != nil {
+			return "", nil, err
+		}
+		delta, err := inflate(br)
+		if err != nil {
+			return "", nil, err
+		}
+		baseType, base, err := r.ReadObject(hex.EncodeToString(baseID))
+		if err != nil {
+			return "", nil, err
+		}
+		data, err := applyDelta(base, delta)
+		return baseType, data, err
+	}
+
+	objType, ok := packTypeNames[entryType]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid pack entry type %d", entryType)
+	}
+	data, err := inflate(br)
+	return objType, data, err
+}
+
+func inflate(r io.Reader) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// applyDelta builds an object from base and a delta of copy and insert instructions.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	errInvalid := errors.New("invalid delta")
+
+	readSize := func() (int, bool) {
+		var size, shift int
+		for len(delta) > 0 {
+			b := delta[0]
+			delta = delta[1:]
+			size |= int(b&0x7f) << shift
+			shift += 7
+			if b&0x80 == 0 {
+				return size, true
+			}
+		}
+		return 0, false
+	}
+
+	baseSize, ok := readSize()
+	if !ok || baseSize != len(base) {
+		return nil, errInvalid
+	}
+	targetSize, ok := readSize()
+	if !ok {
+		return nil, errInvalid
+	}
+
+	target := make([]byte, 0, targetSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		if op&0x80 == 0 {
+			// Insert the next op bytes of the delta
+			n := int(op)
+			if n == 0 || n > len(delta) {
+				return nil, errInvalid
+			}
+			target = append(target, delta[:n]...)
+			delta = delta[n:]
+			continue
+		}
+
+		// Copy from the base, with the bits of op telling which bytes of the offset and size are present
+		var offset, size int
+		for i := 0; i < 7; i++ {
+			if op&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, errInvalid
+			}
+			if i < 4 {
+				offset |= int(delta[0]) << (8 * i)
+			} else {
+				size |= int(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if size == 0 {
+			size = 0x10000
+		}
+		if offset+size > len(base) {
+			return nil, errInvalid
+		}
+		target = append(target, base[offset:offset+size]...)
+	}
+
+	if len(target) != targetSize {
+		return nil, errInvalid
+	}
+	return target, nil
+}
@@ -0,0 +1,236 @@
+// Package history keeps a local record of command durations so each run can be compared against previous runs of the
+// same command, for example in a cache directory restored between CI runs.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// FileName is the name of the history file within the history directory.
+	FileName = "history.jsonl"
+
+	// DefaultWindow is the number of most recent successful runs used as the baseline.
+	DefaultWindow = 20
+	// DefaultMinSamples is the number of successful runs needed before a baseline is computed.
+	DefaultMinSamples = 5
+	// DefaultPercentile is the percentile of the baseline a run has to exceed to be flagged as a regression.
+	DefaultPercentile = 90
+	// MaxEntriesPerKey is the number of most recent runs of each command kept when the history is trimmed.
+	MaxEntriesPerKey = 100
+)
+
+// Span attributes comparing a run with its baseline.
+const (
+	BaselineP50Key = attribute.Key("traci.duration.baseline_p50")
+	RegressedKey   = attribute.Key("traci.duration.regressed")
+)
+
+// Key identifies the runs of the same command.
+type Key struct {
+	Service string `json:"service"`
+	Span    string `json:"span"`
+	Command string `json:"command"`
+}
+
+// Entry is a single run of a command.
+type Entry struct {
+	Key
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration_ns"`
+	ExitCode int           `json:"exit_code"`
+}
+
+// History is a JSON lines file of entries, appended to by every run.
+type History struct {
+	path string
+}
+
+// Open returns the History stored in dir. The directory and file are created on the first Append.
+func Open(dir string) *History {
+	return &History{path: filepath.Join(dir, FileName)}
+}
+
+// Read returns every entry in the history in the order they were appended. A missing history is empty. Lines which
+// can't be decoded, such as an append truncated by a killed run, are skipped.
+func (h *History) Read() ([]Entry, error) {
+	f, err := os.Open(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			slog.Debug(fmt.Sprintf("skipping %s:%d: %v", h.path, line, err))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Append adds entry to the end of the history. Each entry is written with a single write so concurrent runs do not
+// interleave.
+func (h *History) Append(entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Trim rewrites the history keeping the last maxPerKey entries of every command, so a history restored between runs
+// doesn't grow without bound. The file is replaced through a rename so readers never see a partial history, but
+// entries appended by concurrent runs during the rewrite are lost.
+func (h *History) Trim(maxPerKey int) error {
+	entries, err := h.Read()
+	if err != nil {
+		return err
+	}
+
+	counts := map[Key]int{}
+	var kept []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if counts[entries[i].Key] < maxPerKey {
+			counts[entries[i].Key]++
+			kept = append(kept, entries[i])
+		}
+	}
+
+	var data []byte
+	for i := len(kept) - 1; i >= 0; i-- {
+		line, err := json.Marshal(kept[i])
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), FileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), h.path)
+}
+
+// Baseline is the distribution of the recent successful durations of a command.
+type Baseline struct {
+	Samples int
+	P50     time.Duration
+	// Threshold is the duration at the percentile a run has to exceed to be flagged as a regression.
+	Threshold time.Duration
+}
+
+// Regressed reports whether d exceeds the baseline threshold.
+func (b Baseline) Regressed(d time.Duration) bool {
+	return d > b.Threshold
+}
+
+// NewBaseline computes the baseline of the last window successful entries for key. It returns false when fewer than
+// minSamples entries are available.
+func NewBaseline(entries []Entry, key Key, window, minSamples int, percentile float64) (Baseline, bool) {
+	var durations []time.Duration
+	for i := len(entries) - 1; i >= 0 && len(durations) < window; i-- {
+		if entries[i].Key == key && entries[i].ExitCode == 0 {
+			durations = append(durations, entries[i].Duration)
+		}
+	}
+	if len(durations) == 0 || len(durations) < minSamples {
+		return Baseline{}, false
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return Baseline{
+		Samples:   len(durations),
+		P50:       Percentile(durations, 50),
+		Threshold: Percentile(durations, percentile),
+	}, true
+}
+
+// Percentile returns the pth percentile of the sorted durations using the nearest-rank method.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// Regression is the latest run of a command which exceeded the baseline of the runs before it.
+type Regression struct {
+	Latest   Entry
+	Baseline Baseline
+}
+
+// Regressions compares the latest successful run of every command with the baseline of the runs before it and returns
+// the runs which exceeded it, slowest first relative to their baseline.
+func Regressions(entries []Entry, window, minSamples int, percentile float64) []Regression {
+	latest := map[Key]int{}
+	for i, entry := range entries {
+		if entry.ExitCode == 0 {
+			latest[entry.Key] = i
+		}
+	}
+
+	var regressions []Regression
+	for key, i := range latest {
+		baseline, ok := NewBaseline(entries[:i], key, window, minSamples, percentile)
+		if ok && baseline.Regressed(entries[i].Duration) {
+			regressions = append(regressions, Regression{Latest: entries[i], Baseline: baseline})
+		}
+	}
+	sort.Slice(regressions, func(i, j int) bool {
+		return regressions[i].ratio() > regressions[j].ratio()
+	})
+	return regressions
+}
+
+func (r Regression) ratio() float64 {
+	if r.Baseline.P50 <= 0 {
+		return 0
+	}
+	return float64(r.Latest.Duration) / float64(r.Baseline.P50)
+}
@@ -0,0 +1,161 @@
+package history
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func entries(key Key, exitCode int, durations ...time.Duration) []Entry {
+	var result []Entry
+	for _, d := range durations {
+		result = append(result, Entry{Key: key, Duration: d, ExitCode: exitCode})
+	}
+	return result
+}
+
+func TestHistoryAppendRead(t *testing.T) {
+	h := Open(t.TempDir())
+
+	got, err := h.Read()
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+
+	key := Key{Service: "svc", Span: "svc:make", Command: "make build"}
+	first := Entry{Key: key, Time: time.Unix(1700000000, 0).UTC(), Duration: time.Second, ExitCode: 0}
+	second := Entry{Key: key, Time: time.Unix(1700000100, 0).UTC(), Duration: 2 * time.Second, ExitCode: 2}
+	assert.Nil(t, h.Append(first))
+	assert.Nil(t, h.Append(second))
+
+	got, err = h.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, []Entry{first, second}, got)
+}
+
+func TestHistoryReadSkipsMalformedLines(t *testing.T) {
+	dir := t.TempDir()
+	h := Open(dir)
+
+	key := Key{Service: "svc", Span: "svc:make", Command: "make build"}
+	first := Entry{Key: key, Time: time.Unix(1700000000, 0).UTC(), Duration: time.Second}
+	assert.Nil(t, h.Append(first))
+	// A run killed while appending leaves a truncated line behind
+	f, err := os.OpenFile(filepath.Join(dir, FileName), os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.Nil(t, err)
+	_, err = f.WriteString(`{"service":"svc","span":"svc:m` + "\n")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	second := Entry{Key: key, Time: time.Unix(1700000100, 0).UTC(), Duration: 2 * time.Second}
+	assert.Nil(t, h.Append(second))
+
+	got, err := h.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, []Entry{first, second}, got)
+}
+
+func TestHistoryTrim(t *testing.T) {
+	h := Open(t.TempDir())
+
+	build := Key{Service: "svc", Span: "svc:make", Command: "make build"}
+	test := Key{Service: "svc", Span: "svc:make", Command: "make test"}
+	var want []Entry
+	for i := 0; i < 5; i++ {
+		buildEntry := Entry{Key: build, Time: time.Unix(int64(1700000000+i), 0).UTC(), Duration: time.Duration(i) * time.Second}
+		assert.Nil(t, h.Append(buildEntry))
+		if i >= 2 {
+			want = append(want, buildEntry)
+		}
+		if i == 3 {
+			testEntry := Entry{Key: test, Time: time.Unix(1700000100, 0).UTC(), Duration: time.Second}
+			assert.Nil(t, h.Append(testEntry))
+			want = append(want, testEntry)
+		}
+	}
+
+	assert.Nil(t, h.Trim(3))
+	got, err := h.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name string
+		p    float64
+		want time.Duration
+	}{
+		{name: "Case for p0", p: 0, want: 1},
+		{name: "Case for p50", p: 50, want: 5},
+		{name: "Case for p90", p: 90, want: 9},
+		{name: "Case for p95", p: 95, want: 10},
+		{name: "Case for p100", p: 100, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Percentile(sorted, tt.p))
+		})
+	}
+}
+
+func TestNewBaseline(t *testing.T) {
+	key := Key{Command: "make"}
+	other := Key{Command: "make test"}
+
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    Baseline
+		wantOk  bool
+	}{
+		{
+			name:    "Case for too few samples",
+			entries: entries(key, 0, time.Second, time.Second),
+			wantOk:  false,
+		},
+		{
+			name: "Case for ignoring failed runs and other commands",
+			entries: append(append(
+				entries(key, 0, 1*time.Second, 2*time.Second, 3*time.Second, 4*time.Second, 5*time.Second),
+				entries(key, 1, time.Millisecond)...),
+				entries(other, 0, time.Hour)...),
+			want:   Baseline{Samples: 5, P50: 3 * time.Second, Threshold: 5 * time.Second},
+			wantOk: true,
+		},
+		{
+			name:    "Case for window of most recent runs",
+			entries: entries(key, 0, time.Hour, time.Hour, 1*time.Second, 2*time.Second, 3*time.Second, 4*time.Second, 5*time.Second),
+			want:    Baseline{Samples: 5, P50: 3 * time.Second, Threshold: 5 * time.Second},
+			wantOk:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewBaseline(tt.entries, key, 5, 3, 90)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegressions(t *testing.T) {
+	slow := Key{Command: "make"}
+	steady := Key{Command: "make test"}
+
+	var history []Entry
+	history = append(history, entries(slow, 0, time.Second, time.Second, time.Second)...)
+	history = append(history, entries(steady, 0, time.Minute, time.Minute, time.Minute, time.Minute)...)
+	history = append(history, entries(slow, 0, 3*time.Second)...)
+	// A slow failed run is not the latest successful run
+	history = append(history, entries(steady, 1, time.Hour)...)
+
+	got := Regressions(history, DefaultWindow, 3, DefaultPercentile)
+	assert.Equal(t, []Regression{
+		{
+			Latest:   Entry{Key: slow, Duration: 3 * time.Second},
+			Baseline: Baseline{Samples: 3, P50: time.Second, Threshold: time.Second},
+		},
+	}, got)
+}
@@ -0,0 +1,381 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bepEvent is a BuildEvent of the Bazel Build Event Protocol as written by --build_event_json_file. Only the events
+// and fields used for spans are decoded.
+type bepEvent struct {
+	ID struct {
+		TargetConfigured *bepLabelID  `json:"targetConfigured"`
+		TargetCompleted  *bepLabelID  `json:"targetCompleted"`
+		ActionCompleted  *bepActionID `json:"actionCompleted"`
+		TestResult       *bepTestID   `json:"testResult"`
+	} `json:"id"`
+	Started      *bepStarted      `json:"started"`
+	Finished     *bepFinished     `json:"finished"`
+	Configured   *bepConfigured   `json:"configured"`
+	Completed    *bepCompleted    `json:"completed"`
+	Action       *bepAction       `json:"action"`
+	TestResult   *bepTestResult   `json:"testResult"`
+	BuildMetrics *bepBuildMetrics `json:"buildMetrics"`
+}
+
+type bepLabelID struct {
+	Label string `json:"label"`
+}
+
+type bepActionID struct {
+	PrimaryOutput string `json:"primaryOutput"`
+	Label         string `json:"label"`
+}
+
+type bepTestID struct {
+	Label   string `json:"label"`
+	Run     int    `json:"run"`
+	Shard   int    `json:"shard"`
+	Attempt int    `json:"attempt"`
+}
+
+type bepStarted struct {
+	UUID             string    `json:"uuid"`
+	StartTimeMillis  bepInt64  `json:"startTimeMillis"`
+	StartTime        time.Time `json:"startTime"`
+	BuildToolVersion string    `json:"buildToolVersion"`
+	Command          string    `json:"command"`
+}
+
+type bepFinished struct {
+	OverallSuccess   bool      `json:"overallSuccess"`
+	FinishTimeMillis bepInt64  `json:"finishTimeMillis"`
+	FinishTime       time.Time `json:"finishTime"`
+	ExitCode         struct {
+		Name string `json:"name"`
+		Code int    `json:"code"`
+	} `json:"exitCode"`
+}
+
+type bepConfigured struct {
+	TargetKind string `json:"targetKind"`
+}
+
+type bepCompleted struct {
+	Success bool `json:"success"`
+}
+
+type bepAction struct {
+	Success         bool                `json:"success"`
+	Type            string              `json:"type"`
+	ExitCode        int                 `json:"exitCode"`
+	StartTime       time.Time           `json:"startTime"`
+	EndTime         time.Time           `json:"endTime"`
+	StrategyDetails []bepStrategyDetail `json:"strategyDetails"`
+}
+
+// bepStrategyDetail is an entry of the strategy details of an action, such as the SpawnExec of the execution log,
+// describing how the action was run. Only the fields of spawns are decoded.
+type bepStrategyDetail struct {
+	Runner   string `json:"runner"`
+	CacheHit bool   `json:"cacheHit"`
+}
+
+type bepTestResult struct {
+	Status                      string    `json:"status"`
+	CachedLocally               bool      `json:"cachedLocally"`
+	TestAttemptStartMillisEpoch bepInt64  `json:"testAttemptStartMillisEpoch"`
+	TestAttemptStart            time.Time `json:"testAttemptStart"`
+	TestAttemptDurationMillis   bepInt64  `json:"testAttemptDurationMillis"`
+	TestAttemptDuration         string    `json:"testAttemptDuration"`
+	ExecutionInfo               struct {
+		Strategy       string `json:"strategy"`
+		CachedRemotely bool   `json:"cachedRemotely"`
+		Hostname       string `json:"hostname"`
+	} `json:"executionInfo"`
+}
+
+type bepBuildMetrics struct {
+	ActionSummary struct {
+		ActionsCreated        bepInt64 `json:"actionsCreated"`
+		ActionsExecuted       bepInt64 `json:"actionsExecuted"`
+		ActionCacheStatistics struct {
+			Hits   bepInt64 `json:"hits"`
+			Misses bepInt64 `json:"misses"`
+		} `json:"actionCacheStatistics"`
+	} `json:"actionSummary"`
+}
+
+// bepInt64 decodes int64 values, which the proto3 JSON mapping writes as strings.
+type bepInt64 int64
+
+func (i *bepInt64) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = bepInt64(value)
+	return nil
+}
+
+// bepTarget collects the events of a single target.
+type bepTarget struct {
+	label      string
+	kind       string
+	completed  *bepCompleted
+	actions    []bepTimed
+	tests      []bepTimed
+	start, end time.Time
+}
+
+// bepTimed is an action or test result along with its reconstructed timing.
+type bepTimed struct {
+	id         bepEvent
+	start, end time.Time
+}
+
+// Bazel reads a JSON Build Event Protocol file from r and records a span for the invocation, with child spans for
+// each target and, below those, spans for each action and test result. Targets span the actions and tests recorded
+// for them; targets without any are recorded with no duration at the end of the invocation.
+func Bazel(ctx context.Context, tracer trace.Tracer, r io.Reader, reportTime time.Time) error {
+	var started *bepStarted
+	var finished *bepFinished
+	var metrics *bepBuildMetrics
+	targets := map[string]*bepTarget{}
+
+	target := func(label string) *bepTarget {
+		t, ok := targets[label]
+		if !ok {
+			t = &bepTarget{label: label}
+			targets[label] = t
+		}
+		return t
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var event bepEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return err
+		}
+
+		switch {
+		case event.Started != nil:
+			started = event.Started
+		case event.Finished != nil:
+			finished = event.Finished
+		case event.BuildMetrics != nil:
+			metrics = event.BuildMetrics
+		case event.ID.TargetConfigured != nil && event.Configured != nil:
+			target(event.ID.TargetConfigured.Label).kind = event.Configured.TargetKind
+		case event.ID.TargetCompleted != nil && event.Completed != nil:
+			target(event.ID.TargetCompleted.Label).completed = event.Completed
+		case event.ID.ActionCompleted != nil && event.Action != nil:
+			if event.Action.StartTime.IsZero() || event.Action.EndTime.IsZero() {
+				continue
+			}
+			t := target(event.ID.ActionCompleted.Label)
+			t.actions = append(t.actions, bepTimed{id: event, start: event.Action.StartTime, end: event.Action.EndTime})
+		case event.ID.TestResult != nil && event.TestResult != nil:
+			start, duration := bepTestTiming(event.TestResult)
+			if start.IsZero() {
+				continue
+			}
+			t := target(event.ID.TestResult.Label)
+			t.tests = append(t.tests, bepTimed{id: event, start: start, end: start.Add(duration)})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if started == nil {
+		return fmt.Errorf("build event protocol file has no started event")
+	}
+
+	end := reportEnd(reportTime)
+	start := bepTime(started.StartTime, started.StartTimeMillis, end)
+	if finished != nil {
+		end = bepTime(finished.FinishTime, finished.FinishTimeMillis, end)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("bazel.invocation_id", started.UUID),
+		attribute.String("bazel.command", started.Command),
+		attribute.String("bazel.version", started.BuildToolVersion),
+	}
+	if metrics != nil {
+		attrs = append(attrs,
+			attribute.Int64("bazel.actions.created", int64(metrics.ActionSummary.ActionsCreated)),
+			attribute.Int64("bazel.actions.executed", int64(metrics.ActionSummary.ActionsExecuted)),
+			attribute.Int64("bazel.action_cache.hits", int64(metrics.ActionSummary.ActionCacheStatistics.Hits)),
+			attribute.Int64("bazel.action_cache.misses", int64(metrics.ActionSummary.ActionCacheStatistics.Misses)),
+		)
+	}
+
+	invocationCtx, invocation := tracer.Start(ctx, strings.TrimSpace("bazel "+started.Command),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	)
+
+	labels := make([]string, 0, len(targets))
+	for label := range targets {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		recordBazelTarget(invocationCtx, tracer, targets[label], end)
+	}
+
+	if finished != nil {
+		invocation.SetAttributes(
+			attribute.Int("bazel.exit_code", finished.ExitCode.Code),
+			attribute.String("bazel.exit_code.name", finished.ExitCode.Name),
+		)
+		if !finished.OverallSuccess {
+			invocation.SetStatus(codes.Error, finished.ExitCode.Name)
+		}
+	} else {
+		invocation.SetStatus(codes.Error, "no build finished event")
+	}
+	invocation.End(trace.WithTimestamp(end))
+
+	return nil
+}
+
+func recordBazelTarget(ctx context.Context, tracer trace.Tracer, target *bepTarget, invocationEnd time.Time) {
+	for _, timed := range append(append([]bepTimed{}, target.actions...), target.tests...) {
+		if target.start.IsZero() || timed.start.Before(target.start) {
+			target.start = timed.start
+		}
+		if timed.end.After(target.end) {
+			target.end = timed.end
+		}
+	}
+	if target.start.IsZero() {
+		target.start, target.end = invocationEnd, invocationEnd
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("bazel.target.label", target.label)}
+	if target.kind != "" {
+		attrs = append(attrs, attribute.String("bazel.target.kind", target.kind))
+	}
+
+	targetCtx, span := tracer.Start(ctx, target.label, trace.WithTimestamp(target.start), trace.WithAttributes(attrs...))
+	defer span.End(trace.WithTimestamp(target.end))
+
+	if target.completed != nil && !target.completed.Success {
+		span.SetStatus(codes.Error, fmt.Sprintf("%s failed", target.label))
+	}
+
+	for _, timed := range target.actions {
+		action := timed.id.Action
+		attrs := []attribute.KeyValue{
+			attribute.String("bazel.action.mnemonic", action.Type),
+			attribute.String("bazel.action.primary_output", timed.id.ID.ActionCompleted.PrimaryOutput),
+			attribute.Int("bazel.action.exit_code", action.ExitCode),
+		}
+		attrs = append(attrs, bepActionExecutionAttributes(action)...)
+		_, actionSpan := tracer.Start(targetCtx, action.Type, trace.WithTimestamp(timed.start), trace.WithAttributes(attrs...))
+		if !action.Success {
+			actionSpan.SetStatus(codes.Error, fmt.Sprintf("%s failed with exit code %d", action.Type, action.ExitCode))
+		}
+		actionSpan.End(trace.WithTimestamp(timed.end))
+	}
+
+	for _, timed := range target.tests {
+		recordBazelTest(targetCtx, tracer, timed)
+	}
+}
+
+// bepActionExecutionAttributes returns the cache and remote execution attributes of an action from its spawn strategy
+// details, named like the ones of test results. Actions without strategy details have none.
+func bepActionExecutionAttributes(action *bepAction) []attribute.KeyValue {
+	for _, detail := range action.StrategyDetails {
+		if detail.Runner == "" {
+			continue
+		}
+		// Runners are the strategy of executed spawns, or describe the cache the result was taken from, such as
+		// `remote cache hit` and `disk cache hit`
+		return []attribute.KeyValue{
+			attribute.Bool("bazel.cached_locally", detail.CacheHit && !strings.HasPrefix(detail.Runner, "remote")),
+			attribute.Bool("bazel.cached_remotely", detail.CacheHit && strings.HasPrefix(detail.Runner, "remote")),
+			attribute.String("bazel.strategy", detail.Runner),
+			attribute.Bool("bazel.remote_execution", !detail.CacheHit && detail.Runner == "remote"),
+		}
+	}
+	return nil
+}
+
+func recordBazelTest(ctx context.Context, tracer trace.Tracer, timed bepTimed) {
+	id := timed.id.ID.TestResult
+	result := timed.id.TestResult
+
+	name := id.Label
+	if id.Run > 1 || id.Shard > 1 || id.Attempt > 1 {
+		name = fmt.Sprintf("%s (run %d, shard %d, attempt %d)", id.Label, id.Run, id.Shard, id.Attempt)
+	}
+
+	_, span := tracer.Start(ctx, name,
+		trace.WithTimestamp(timed.start),
+		trace.WithAttributes(
+			TestSuiteNameKey.String(id.Label),
+			TestCaseNameKey.String(id.Label),
+			attribute.Int("bazel.test.run", id.Run),
+			attribute.Int("bazel.test.shard", id.Shard),
+			attribute.Int("bazel.test.attempt", id.Attempt),
+			attribute.String("bazel.test.status", result.Status),
+			attribute.Bool("bazel.cached_locally", result.CachedLocally),
+			attribute.Bool("bazel.cached_remotely", result.ExecutionInfo.CachedRemotely),
+			attribute.String("bazel.strategy", result.ExecutionInfo.Strategy),
+			attribute.Bool("bazel.remote_execution", result.ExecutionInfo.Strategy == "remote"),
+		),
+	)
+
+	switch result.Status {
+	case "PASSED", "FLAKY":
+		setResult(span, ResultPass, "")
+	case "NO_STATUS", "":
+		setResult(span, ResultSkip, "")
+	default:
+		setResult(span, ResultFail, fmt.Sprintf("%s %s", id.Label, strings.ToLower(result.Status)))
+	}
+
+	span.End(trace.WithTimestamp(timed.end))
+}
+
+// bepTestTiming returns the start time and duration of a test attempt, preferring the timestamp fields of newer Bazel
+// versions over the deprecated millisecond fields.
+func bepTestTiming(result *bepTestResult) (time.Time, time.Duration) {
+	start := bepTime(result.TestAttemptStart, result.TestAttemptStartMillisEpoch, time.Time{})
+	duration := time.Duration(result.TestAttemptDurationMillis) * time.Millisecond
+	if d, err := time.ParseDuration(result.TestAttemptDuration); err == nil {
+		duration = d
+	}
+	return start, duration
+}
+
+// bepTime returns timestamp if set, otherwise the time of the millisecond epoch value, or fallback if neither is set.
+func bepTime(timestamp time.Time, millis bepInt64, fallback time.Time) time.Time {
+	if !timestamp.IsZero() {
+		return timestamp
+	}
+	if millis > 0 {
+		return time.UnixMilli(int64(millis))
+	}
+	return fallback
+}
@@ -0,0 +1,65 @@
+package ingest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const bazelEvents = `{"id":{"started":{}},"started":{"uuid":"abc-123","startTimeMillis":"1704103200000","buildToolVersion":"7.0.0","command":"test"}}
+{"id":{"targetConfigured":{"label":"//foo:lib"}},"configured":{"targetKind":"go_library rule"}}
+{"id":{"targetConfigured":{"label":"//foo:test"}},"configured":{"targetKind":"go_test rule"}}
+{"id":{"actionCompleted":{"primaryOutput":"bazel-out/foo/lib.a","label":"//foo:lib"}},"action":{"success":false,"type":"GoCompilePkg","exitCode":1,"startTime":"2024-01-01T10:00:01Z","endTime":"2024-01-01T10:00:03Z"}}
+{"id":{"actionCompleted":{"primaryOutput":"bazel-out/foo/gen.go","label":"//foo:lib"}},"action":{"success":true,"type":"Genrule","exitCode":0,"startTime":"2024-01-01T10:00:01Z","endTime":"2024-01-01T10:00:02Z","strategyDetails":[{"@type":"type.googleapis.com/tools.protos.SpawnExec","runner":"remote cache hit","cacheHit":true}]}}
+{"id":{"targetCompleted":{"label":"//foo:lib"}},"completed":{"success":false}}
+{"id":{"testResult":{"label":"//foo:test","run":1,"shard":1,"attempt":1}},"testResult":{"status":"PASSED","cachedLocally":false,"testAttemptStart":"2024-01-01T10:00:02Z","testAttemptDuration":"1.500s","executionInfo":{"strategy":"remote","cachedRemotely":true}}}
+{"id":{"targetCompleted":{"label":"//foo:test"}},"completed":{"success":true}}
+{"id":{"buildMetrics":{}},"buildMetrics":{"actionSummary":{"actionsCreated":"10","actionsExecuted":"4","actionCacheStatistics":{"hits":"6","misses":"4"}}}}
+{"id":{"buildFinished":{}},"finished":{"overallSuccess":false,"exitCode":{"name":"BUILD_FAILURE","code":1},"finishTimeMillis":"1704103210000"},"lastMessage":true}
+`
+
+func TestBazel(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+
+	err := Bazel(ctx, tracer, strings.NewReader(bazelEvents), time.Time{})
+	assert.Nil(t, err)
+
+	// The test result span shares its name with its target
+	assert.Len(t, recorder.Ended(), 6)
+	spans := spansByName(recorder)
+
+	invocation := spans["bazel test"]
+	assert.Equal(t, time.UnixMilli(1704103200000), invocation.StartTime())
+	assert.Equal(t, time.UnixMilli(1704103210000), invocation.EndTime())
+	assert.Equal(t, codes.Error, invocation.Status().Code)
+	assert.Equal(t, "6", attributeValue(invocation, "bazel.action_cache.hits"))
+
+	lib := spans["//foo:lib"]
+	assert.Equal(t, invocation.SpanContext().SpanID(), lib.Parent().SpanID())
+	assert.Equal(t, codes.Error, lib.Status().Code)
+	assert.Equal(t, "go_library rule", attributeValue(lib, "bazel.target.kind"))
+	assert.True(t, time.Date(2024, 1, 1, 10, 0, 1, 0, time.UTC).Equal(lib.StartTime()))
+
+	action := spans["GoCompilePkg"]
+	assert.Equal(t, lib.SpanContext().SpanID(), action.Parent().SpanID())
+	assert.Equal(t, 2*time.Second, action.EndTime().Sub(action.StartTime()))
+	assert.Equal(t, codes.Error, action.Status().Code)
+	assert.Equal(t, "", attributeValue(action, "bazel.strategy"))
+
+	cached := spans["Genrule"]
+	assert.Equal(t, "true", attributeValue(cached, "bazel.cached_remotely"))
+	assert.Equal(t, "false", attributeValue(cached, "bazel.cached_locally"))
+	assert.Equal(t, "false", attributeValue(cached, "bazel.remote_execution"))
+	assert.Equal(t, "remote cache hit", attributeValue(cached, "bazel.strategy"))
+
+	test := spans["//foo:test"]
+	testResult := recorder.Ended()[3]
+	assert.Equal(t, "//foo:test", testResult.Name())
+	assert.Equal(t, test.SpanContext().SpanID(), testResult.Parent().SpanID())
+	assert.Equal(t, 1500*time.Millisecond, testResult.EndTime().Sub(testResult.StartTime()))
+	assert.Equal(t, ResultPass, attributeValue(testResult, TestCaseResultStatusKey))
+	assert.Equal(t, "true", attributeValue(testResult, "bazel.remote_execution"))
+	assert.Equal(t, "true", attributeValue(testResult, "bazel.cached_remotely"))
+}
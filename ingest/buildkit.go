@@ -0,0 +1,140 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"time"
+)
+
+// buildkitSolveStatus is a line of `docker buildx build --progress rawjson` output.
+type buildkitSolveStatus struct {
+	Vertexes []buildkitVertex `json:"vertexes"`
+	Statuses []buildkitStatus `json:"statuses"`
+}
+
+type buildkitVertex struct {
+	Digest    string     `json:"digest"`
+	Inputs    []string   `json:"inputs"`
+	Name      string     `json:"name"`
+	Started   *time.Time `json:"started"`
+	Completed *time.Time `json:"completed"`
+	Cached    bool       `json:"cached"`
+	Error     string     `json:"error"`
+}
+
+type buildkitStatus struct {
+	ID        string     `json:"id"`
+	Vertex    string     `json:"vertex"`
+	Name      string     `json:"name"`
+	Total     int64      `json:"total"`
+	Current   int64      `json:"current"`
+	Started   *time.Time `json:"started"`
+	Completed *time.Time `json:"completed"`
+}
+
+// BuildKitRawJSON reads the rawjson progress stream of a BuildKit build from r and records a span for each vertex,
+// with child spans for the statuses of the vertex such as layer downloads. Vertex updates are merged by digest and
+// vertices which never started, or never completed, are skipped.
+func BuildKitRawJSON(ctx context.Context, tracer trace.Tracer, r io.Reader, _ time.Time) error {
+	var order []string
+	vertexes := map[string]*buildkitVertex{}
+	statuses := map[string][]*buildkitStatus{}
+	statusByID := map[string]*buildkitStatus{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var solveStatus buildkitSolveStatus
+		if err := json.Unmarshal(scanner.Bytes(), &solveStatus); err != nil {
+			continue
+		}
+
+		for _, update := range solveStatus.Vertexes {
+			update := update
+			vertex, ok := vertexes[update.Digest]
+			if !ok {
+				vertexes[update.Digest] = &update
+				order = append(order, update.Digest)
+				continue
+			}
+			if update.Started != nil {
+				vertex.Started = update.Started
+			}
+			if update.Completed != nil {
+				vertex.Completed = update.Completed
+			}
+			vertex.Cached = vertex.Cached || update.Cached
+			if update.Error != "" {
+				vertex.Error = update.Error
+			}
+		}
+
+		for _, update := range solveStatus.Statuses {
+			update := update
+			key := update.Vertex + "/" + update.ID
+			status, ok := statusByID[key]
+			if !ok {
+				statusByID[key] = &update
+				statuses[update.Vertex] = append(statuses[update.Vertex], &update)
+				continue
+			}
+			if update.Started != nil {
+				status.Started = update.Started
+			}
+			if update.Completed != nil {
+				status.Completed = update.Completed
+			}
+			status.Current, status.Total = update.Current, update.Total
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, digest := range order {
+		vertex := vertexes[digest]
+		if vertex.Started == nil || vertex.Completed == nil {
+			continue
+		}
+
+		vertexCtx, span := tracer.Start(ctx, vertex.Name,
+			trace.WithTimestamp(*vertex.Started),
+			trace.WithAttributes(
+				attribute.String("buildkit.vertex.digest", vertex.Digest),
+				attribute.StringSlice("buildkit.vertex.inputs", vertex.Inputs),
+				attribute.Bool("buildkit.vertex.cached", vertex.Cached),
+			),
+		)
+
+		for _, status := range statuses[digest] {
+			if status.Started == nil || status.Completed == nil {
+				continue
+			}
+			name := status.Name
+			if name == "" {
+				name = status.ID
+			}
+			_, statusSpan := tracer.Start(vertexCtx, name,
+				trace.WithTimestamp(*status.Started),
+				trace.WithAttributes(
+					attribute.String("buildkit.status.id", status.ID),
+					attribute.Int64("buildkit.status.current", status.Current),
+					attribute.Int64("buildkit.status.total", status.Total),
+				),
+			)
+			statusSpan.End(trace.WithTimestamp(*status.Completed))
+		}
+
+		if vertex.Error != "" {
+			span.SetStatus(codes.Error, vertex.Error)
+		}
+		span.End(trace.WithTimestamp(*vertex.Completed))
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package ingest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const buildkitStream = `{"vertexes":[{"digest":"sha256:aaa","name":"[1/2] FROM alpine","started":"2024-01-01T10:00:00Z"}]}
+{"statuses":[{"id":"layer1","vertex":"sha256:aaa","name":"downloading layer1","total":100,"current":10,"started":"2024-01-01T10:00:00.5Z"}]}
+{"statuses":[{"id":"layer1","vertex":"sha256:aaa","name":"downloading layer1","total":100,"current":100,"started":"2024-01-01T10:00:00.5Z","completed":"2024-01-01T10:00:01Z"}]}
+{"vertexes":[{"digest":"sha256:aaa","name":"[1/2] FROM alpine","started":"2024-01-01T10:00:00Z","completed":"2024-01-01T10:00:02Z"}]}
+{"vertexes":[{"digest":"sha256:bbb","inputs":["sha256:aaa"],"name":"[2/2] RUN make","started":"2024-01-01T10:00:02Z"}]}
+{"vertexes":[{"digest":"sha256:bbb","inputs":["sha256:aaa"],"name":"[2/2] RUN make","started":"2024-01-01T10:00:02Z","completed":"2024-01-01T10:00:05Z","error":"process did not complete successfully"}]}
+{"vertexes":[{"digest":"sha256:ccc","name":"[internal] never started"}]}
+`
+
+func TestBuildKitRawJSON(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+
+	err := BuildKitRawJSON(ctx, tracer, strings.NewReader(buildkitStream), time.Time{})
+	assert.Nil(t, err)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans, 3)
+
+	from := spans["[1/2] FROM alpine"]
+	assert.Equal(t, 2*time.Second, from.EndTime().Sub(from.StartTime()))
+	assert.Equal(t, "sha256:aaa", attributeValue(from, "buildkit.vertex.digest"))
+
+	download := spans["downloading layer1"]
+	assert.Equal(t, from.SpanContext().SpanID(), download.Parent().SpanID())
+	assert.Equal(t, "100", attributeValue(download, "buildkit.status.current"))
+
+	run := spans["[2/2] RUN make"]
+	assert.Equal(t, codes.Error, run.Status().Code)
+	assert.Equal(t, "[sha256:aaa]", attributeValue(run, "buildkit.vertex.inputs"))
+}
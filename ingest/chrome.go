@@ -0,0 +1,214 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"sort"
+	"time"
+)
+
+// chromeEpochThreshold separates absolute timestamps, in microseconds since the unix epoch, from timestamps relative
+// to the start of a trace. It is the year 2001 in microseconds.
+const chromeEpochThreshold = 1e15
+
+// chromeEvent is an event of the Chrome trace-event format, see
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type chromeEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat"`
+	Ph   string                 `json:"ph"`
+	Ts   float64                `json:"ts"`
+	Dur  float64                `json:"dur"`
+	Pid  json.Number            `json:"pid"`
+	Tid  json.Number            `json:"tid"`
+	Args map[string]interface{} `json:"args"`
+}
+
+type chromeTraceObject struct {
+	TraceEvents []chromeEvent `json:"traceEvents"`
+}
+
+// chromeSlice is a complete duration event on a thread, with its timestamps in microseconds.
+type chromeSlice struct {
+	event      chromeEvent
+	start, end float64
+}
+
+type chromeThread struct {
+	pid, tid string
+}
+
+// ChromeTrace reads a Chrome trace-event file from r, in either the JSON array or JSON object format, and records a
+// span for each complete (X) event and each matched pair of begin (B) and end (E) events. Events on the same thread
+// are nested by time. Timestamps before the year 2001 are treated as relative to the start of the trace and placed so
+// the trace ends at reportTime, or when reading finished for streamed files.
+func ChromeTrace(ctx context.Context, tracer trace.Tracer, r io.Reader, reportTime time.Time) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	events, err := parseChromeEvents(data)
+	if err != nil {
+		return err
+	}
+
+	processNames := map[string]string{}
+	threadNames := map[chromeThread]string{}
+	open := map[chromeThread][]chromeEvent{}
+	slices := map[chromeThread][]chromeSlice{}
+
+	for _, event := range events {
+		thread := chromeThread{pid: event.Pid.String(), tid: event.Tid.String()}
+		switch event.Ph {
+		case "X":
+			slices[thread] = append(slices[thread], chromeSlice{event: event, start: event.Ts, end: event.Ts + event.Dur})
+		case "B":
+			open[thread] = append(open[thread], event)
+		case "E":
+			stack := open[thread]
+			if len(stack) == 0 {
+				continue
+			}
+			begin := stack[len(stack)-1]
+			open[thread] = stack[:len(stack)-1]
+			for k, v := range event.Args {
+				if begin.Args == nil {
+					begin.Args = map[string]interface{}{}
+				}
+				begin.Args[k] = v
+			}
+			slices[thread] = append(slices[thread], chromeSlice{event: begin, start: begin.Ts, end: event.Ts})
+		case "M":
+			if name, ok := event.Args["name"].(string); ok {
+				switch event.Name {
+				case "process_name":
+					processNames[thread.pid] = name
+				case "thread_name":
+					threadNames[thread] = name
+				}
+			}
+		}
+	}
+
+	// Place relative timestamps so the latest event ends at the time the trace was written
+	var latest float64
+	for _, threadSlices := range slices {
+		for _, slice := range threadSlices {
+			if slice.end > latest {
+				latest = slice.end
+			}
+		}
+	}
+	var origin time.Time
+	if latest < chromeEpochThreshold {
+		origin = reportEnd(reportTime).Add(-microseconds(latest))
+	} else {
+		origin = time.Unix(0, 0)
+	}
+
+	threads := make([]chromeThread, 0, len(slices))
+	for thread := range slices {
+		threads = append(threads, thread)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		if threads[i].pid == threads[j].pid {
+			return threads[i].tid < threads[j].tid
+		}
+		return threads[i].pid < threads[j].pid
+	})
+
+	for _, thread := range threads {
+		threadAttrs := []attribute.KeyValue{
+			attribute.String("chrome.pid", thread.pid),
+			attribute.String("chrome.tid", thread.tid),
+		}
+		if name, ok := processNames[thread.pid]; ok {
+			threadAttrs = append(threadAttrs, attribute.String("chrome.process_name", name))
+		}
+		if name, ok := threadNames[thread]; ok {
+			threadAttrs = append(threadAttrs, attribute.String("chrome.thread_name", name))
+		}
+		recordChromeThread(ctx, tracer, slices[thread], origin, threadAttrs)
+	}
+
+	return nil
+}
+
+// recordChromeThread records the slices of a single thread, nesting each slice under the enclosing slice.
+func recordChromeThread(ctx context.Context, tracer trace.Tracer, slices []chromeSlice, origin time.Time, threadAttrs []attribute.KeyValue) {
+	sort.SliceStable(slices, func(i, j int) bool {
+		if slices[i].start == slices[j].start {
+			return slices[i].end > slices[j].end
+		}
+		return slices[i].start < slices[j].start
+	})
+
+	type openSlice struct {
+		ctx  context.Context
+		span trace.Span
+		end  float64
+	}
+	var stack []openSlice
+	for _, slice := range slices {
+		for len(stack) > 0 && stack[len(stack)-1].end <= slice.start {
+			stack[len(stack)-1].span.End(trace.WithTimestamp(origin.Add(microseconds(stack[len(stack)-1].end))))
+			stack = stack[:len(stack)-1]
+		}
+
+		parent := ctx
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1].ctx
+		}
+
+		attrs := append([]attribute.KeyValue{}, threadAttrs...)
+		if slice.event.Cat != "" {
+			attrs = append(attrs, attribute.String("chrome.category", slice.event.Cat))
+		}
+		for k, v := range slice.event.Args {
+			attrs = append(attrs, attribute.String("chrome.args."+k, fmt.Sprint(v)))
+		}
+
+		sliceCtx, span := tracer.Start(parent, slice.event.Name, trace.WithTimestamp(origin.Add(microseconds(slice.start))), trace.WithAttributes(attrs...))
+		stack = append(stack, openSlice{ctx: sliceCtx, span: span, end: slice.end})
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].span.End(trace.WithTimestamp(origin.Add(microseconds(stack[i].end))))
+	}
+}
+
+// parseChromeEvents decodes the JSON object format or the JSON array format, where the closing bracket is optional.
+func parseChromeEvents(data []byte) ([]chromeEvent, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var object chromeTraceObject
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, err
+		}
+		return object.TraceEvents, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	var events []chromeEvent
+	for decoder.More() {
+		var event chromeEvent
+		if err := decoder.Decode(&event); err != nil {
+			// Traces written by a process which was killed can end with a partial event
+			break
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func microseconds(us float64) time.Duration {
+	return time.Duration(us * float64(time.Microsecond))
+}
@@ -0,0 +1,59 @@
+package ingest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChromeTrace(t *testing.T) {
+	testCases := []struct {
+		name       string
+		trace      string
+		reportTime time.Time
+		wantStart  time.Time
+	}{
+		{
+			name: "Relative timestamps in array format without closing bracket",
+			trace: `[{"name":"thread_name","ph":"M","pid":1,"tid":2,"args":{"name":"worker"}},
+{"name":"compile","cat":"build","ph":"X","ts":0,"dur":3000000,"pid":1,"tid":2},
+{"name":"link","ph":"B","ts":1000000,"pid":1,"tid":2,"args":{"target":"app"}},
+{"name":"link","ph":"E","ts":2000000,"pid":1,"tid":2},`,
+			reportTime: time.Date(2024, 1, 1, 10, 0, 3, 0, time.UTC),
+			wantStart:  time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Absolute timestamps in object format",
+			trace: `{"traceEvents":[{"name":"thread_name","ph":"M","pid":1,"tid":2,"args":{"name":"worker"}},
+{"name":"compile","cat":"build","ph":"X","ts":1704103200000000,"dur":3000000,"pid":1,"tid":2},
+{"name":"link","ph":"B","ts":1704103201000000,"pid":1,"tid":2,"args":{"target":"app"}},
+{"name":"link","ph":"E","ts":1704103202000000,"pid":1,"tid":2}]}`,
+			reportTime: time.Now(),
+			wantStart:  time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder, tracer, ctx := newTestTracer()
+
+			err := ChromeTrace(ctx, tracer, strings.NewReader(tc.trace), tc.reportTime)
+			assert.Nil(t, err)
+
+			spans := spansByName(recorder)
+			assert.Len(t, spans, 2)
+
+			compile := spans["compile"]
+			assert.WithinDuration(t, tc.wantStart, compile.StartTime(), 0)
+			assert.Equal(t, 3*time.Second, compile.EndTime().Sub(compile.StartTime()))
+			assert.Equal(t, "worker", attributeValue(compile, "chrome.thread_name"))
+			assert.Equal(t, "build", attributeValue(compile, "chrome.category"))
+
+			link := spans["link"]
+			assert.Equal(t, compile.SpanContext().SpanID(), link.Parent().SpanID())
+			assert.Equal(t, time.Second, link.EndTime().Sub(link.StartTime()))
+			assert.Equal(t, "app", attributeValue(link, "chrome.args.target"))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"strings"
+	"time"
+)
+
+// goTestEvent is a single event of the test2json stream, see `go doc test2json`.
+type goTestEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// goTestSpan is a package or test span which has been started but has not received a result yet.
+type goTestSpan struct {
+	name   string
+	ctx    context.Context
+	span   trace.Span
+	output strings.Builder
+}
+
+// GoTest reads a `go test -json` event stream from r and records a span for each package with a child span for each
+// test; subtests are nested under their parent test. Output of failed tests and packages is attached as an event.
+// If out is not nil, the test output is written to it as it is read, reproducing the regular `go test` output.
+func GoTest(ctx context.Context, tracer trace.Tracer, r io.Reader, out io.Writer) error {
+	packages := map[string]*goTestSpan{}
+	tests := map[string]*goTestSpan{}
+	var last time.Time
+
+	startPackage := func(event goTestEvent) *goTestSpan {
+		if p, ok := packages[event.Package]; ok {
+			return p
+		}
+		pctx, span := tracer.Start(ctx, event.Package,
+			trace.WithTimestamp(event.Time),
+			trace.WithAttributes(TestSuiteNameKey.String(event.Package)),
+		)
+		p := &goTestSpan{name: event.Package, ctx: pctx, span: span}
+		packages[event.Package] = p
+		return p
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var event goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Action == "" {
+			// Build failures and other tool output can be interleaved with the JSON stream
+			if out != nil {
+				fmt.Fprintln(out, scanner.Text())
+			}
+			continue
+		}
+		if event.Time.IsZero() {
+			event.Time = time.Now()
+		}
+		last = event.Time
+
+		if out != nil && event.Output != "" {
+			fmt.Fprint(out, event.Output)
+		}
+		if event.Package == "" {
+			continue
+		}
+
+		pkg := startPackage(event)
+		key := event.Package + " " + event.Test
+
+		switch event.Action {
+		case "run":
+			parent := pkg
+			if i := strings.LastIndex(event.Test, "/"); i > 0 {
+				if t, ok := tests[event.Package+" "+event.Test[:i]]; ok {
+					parent = t
+				}
+			}
+			tctx, span := tracer.Start(parent.ctx, event.Test,
+				trace.WithTimestamp(event.Time),
+				trace.WithAttributes(
+					TestSuiteNameKey.String(event.Package),
+					TestCaseNameKey.String(event.Test),
+				),
+			)
+			tests[key] = &goTestSpan{name: event.Test, ctx: tctx, span: span}
+		case "output", "build-output":
+			if event.Test == "" {
+				pkg.output.WriteString(event.Output)
+			} else if t, ok := tests[key]; ok {
+				t.output.WriteString(event.Output)
+			}
+		case "pass", "fail", "skip":
+			target := pkg
+			if event.Test != "" {
+				t, ok := tests[key]
+				if !ok {
+					continue
+				}
+				target = t
+				delete(tests, key)
+			} else {
+				delete(packages, event.Package)
+			}
+			endGoTestSpan(target, event.Action, event.Time, event.Elapsed)
+		}
+	}
+
+	// Close anything left open, e.g. when the test binary panicked or timed out
+	for _, t := range tests {
+		t.span.SetStatus(codes.Error, "no test result reported")
+		t.span.End(trace.WithTimestamp(last))
+	}
+	for _, p := range packages {
+		p.span.SetStatus(codes.Error, "no package result reported")
+		p.span.End(trace.WithTimestamp(last))
+	}
+
+	return scanner.Err()
+}
+
+// endGoTestSpan records the result of a package or test and ends its span.
+func endGoTestSpan(t *goTestSpan, action string, end time.Time, elapsed float64) {
+	if action == ResultFail && t.output.Len() > 0 {
+		t.span.AddEvent("test.output", trace.WithTimestamp(end), trace.WithAttributes(
+			attribute.String("test.output", t.output.String()),
+		))
+	}
+	setResult(t.span, action, fmt.Sprintf("%s failed", t.name))
+	t.span.SetAttributes(attribute.Float64("test.elapsed", elapsed))
+	t.span.End(trace.WithTimestamp(end))
+}
@@ -0,0 +1,58 @@
+package ingest
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"testing"
+	"time"
+)
+
+const goTestStream = `{"Time":"2024-01-01T10:00:00Z","Action":"start","Package":"example.com/foo"}
+{"Time":"2024-01-01T10:00:00.1Z","Action":"run","Package":"example.com/foo","Test":"TestA"}
+{"Time":"2024-01-01T10:00:00.1Z","Action":"output","Package":"example.com/foo","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Time":"2024-01-01T10:00:00.2Z","Action":"run","Package":"example.com/foo","Test":"TestA/sub"}
+{"Time":"2024-01-01T10:00:00.3Z","Action":"output","Package":"example.com/foo","Test":"TestA/sub","Output":"    foo_test.go:10: boom\n"}
+{"Time":"2024-01-01T10:00:00.4Z","Action":"fail","Package":"example.com/foo","Test":"TestA/sub","Elapsed":0.2}
+{"Time":"2024-01-01T10:00:00.5Z","Action":"fail","Package":"example.com/foo","Test":"TestA","Elapsed":0.4}
+{"Time":"2024-01-01T10:00:00.6Z","Action":"run","Package":"example.com/foo","Test":"TestB"}
+{"Time":"2024-01-01T10:00:00.7Z","Action":"skip","Package":"example.com/foo","Test":"TestB","Elapsed":0.1}
+# example.com/bar
+{"Time":"2024-01-01T10:00:01Z","Action":"fail","Package":"example.com/foo","Elapsed":1}
+`
+
+func TestGoTest(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+	out := new(bytes.Buffer)
+
+	err := GoTest(ctx, tracer, strings.NewReader(goTestStream), out)
+	assert.Nil(t, err)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans, 4)
+
+	pkg := spans["example.com/foo"]
+	assert.Equal(t, trace.TraceID{1}, pkg.Parent().TraceID())
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), pkg.StartTime())
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 1, 0, time.UTC), pkg.EndTime())
+	assert.Equal(t, codes.Error, pkg.Status().Code)
+
+	testA := spans["TestA"]
+	assert.Equal(t, pkg.SpanContext().SpanID(), testA.Parent().SpanID())
+	assert.Equal(t, ResultFail, attributeValue(testA, TestCaseResultStatusKey))
+
+	sub := spans["TestA/sub"]
+	assert.Equal(t, testA.SpanContext().SpanID(), sub.Parent().SpanID())
+	assert.Equal(t, codes.Error, sub.Status().Code)
+	if assert.Len(t, sub.Events(), 1) {
+		assert.Equal(t, "    foo_test.go:10: boom\n", sub.Events()[0].Attributes[0].Value.AsString())
+	}
+
+	testB := spans["TestB"]
+	assert.Equal(t, ResultSkip, attributeValue(testB, TestCaseResultStatusKey))
+	assert.Equal(t, codes.Unset, testB.Status().Code)
+
+	assert.Equal(t, "=== RUN   TestA\n    foo_test.go:10: boom\n# example.com/bar\n", out.String())
+}
@@ -0,0 +1,65 @@
+// Package ingest converts test and build reports produced by other tools into spans, keeping the timing recorded in
+// the report rather than the time the report is read.
+package ingest
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// Test attribute keys shared by all test report formats.
+const (
+	TestSuiteNameKey        = attribute.Key("test.suite.name")
+	TestCaseNameKey         = attribute.Key("test.case.name")
+	TestCaseResultStatusKey = attribute.Key("test.case.result.status")
+	TestCaseResultReasonKey = attribute.Key("test.case.result.reason")
+)
+
+// Test results recorded under TestCaseResultStatusKey.
+const (
+	ResultPass  = "pass"
+	ResultFail  = "fail"
+	ResultError = "error"
+	ResultSkip  = "skip"
+)
+
+// setResult records a test result on the span, marking failures and errors as errors with the given description.
+func setResult(span trace.Span, result string, description string) {
+	span.SetAttributes(TestCaseResultStatusKey.String(result))
+	if result == ResultFail || result == ResultError {
+		span.SetStatus(codes.Error, description)
+	}
+}
+
+// recordException adds an exception event to the span, following the OpenTelemetry exception semantic conventions.
+func recordException(span trace.Span, exceptionType, message, stacktrace string, timestamp time.Time) {
+	attrs := []attribute.KeyValue{semconv.ExceptionMessage(message)}
+	if exceptionType != "" {
+		attrs = append(attrs, semconv.ExceptionType(exceptionType))
+	}
+	if stacktrace != "" {
+		attrs = append(attrs, semconv.ExceptionStacktrace(stacktrace))
+	}
+	span.AddEvent(semconv.ExceptionEventName, trace.WithTimestamp(timestamp), trace.WithAttributes(attrs...))
+}
+
+// reportEnd returns the time a report ended, which is reportTime for report files and now for streamed reports, which
+// have a zero reportTime.
+func reportEnd(reportTime time.Time) time.Time {
+	if reportTime.IsZero() {
+		return time.Now()
+	}
+	return reportTime
+}
+
+// arrivalOffset returns the offset to add to the times events of a report were read at so the report ends at
+// reportTime. Streamed reports keep the times their events arrived at.
+func arrivalOffset(reportTime time.Time, lastArrival time.Time) time.Duration {
+	if reportTime.IsZero() || lastArrival.IsZero() {
+		return 0
+	}
+	return reportTime.Sub(lastArrival)
+}
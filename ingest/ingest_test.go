@@ -0,0 +1,68 @@
+package ingest
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestSetResult(t *testing.T) {
+	testCases := []struct {
+		name       string
+		result     string
+		wantStatus codes.Code
+	}{
+		{name: "Pass", result: ResultPass, wantStatus: codes.Unset},
+		{name: "Skip", result: ResultSkip, wantStatus: codes.Unset},
+		{name: "Fail", result: ResultFail, wantStatus: codes.Error},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder, tracer, ctx := newTestTracer()
+			_, span := tracer.Start(ctx, "test")
+			setResult(span, tc.result, "failed")
+			span.End()
+
+			got := recorder.Ended()[0]
+			assert.Equal(t, tc.wantStatus, got.Status().Code)
+			assert.Equal(t, tc.result, attributeValue(got, TestCaseResultStatusKey))
+		})
+	}
+}
+
+// newTestTracer returns a tracer recording ended spans and a context holding a root span to parent them to.
+func newTestTracer() (*tracetest.SpanRecorder, trace.Tracer, context.Context) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	return recorder, tracer, ctx
+}
+
+// spansByName indexes the ended spans of the recorder by their name.
+func spansByName(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	return spans
+}
+
+// attributeValue returns the value of the attribute key on the span as a string.
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
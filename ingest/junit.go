@@ -0,0 +1,245 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// junitTimestampLayouts are the timestamp formats written by common JUnit reporters. Layouts without a zone are
+// interpreted in the local time zone, matching reporters such as maven surefire.
+var junitTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+type junitTestSuites struct {
+	Suites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Timestamp  string           `xml:"timestamp,attr"`
+	Time       string           `xml:"time,attr"`
+	Hostname   string           `xml:"hostname,attr"`
+	Properties []junitProperty  `xml:"properties>property"`
+	Cases      []junitTestCase  `xml:"testcase"`
+	Suites     []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestCase struct {
+	Name       string          `xml:"name,attr"`
+	ClassName  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	File       string          `xml:"file,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Failures   []junitFailure  `xml:"failure"`
+	Errors     []junitFailure  `xml:"error"`
+	Skipped    *junitFailure   `xml:"skipped"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit reads a JUnit XML report from r and records a span for each testsuite with a child span for each testcase.
+// Test cases are assumed to run sequentially from the suite timestamp. Suites without a timestamp are placed to end at
+// reportTime, or when reading finished for streamed reports. Failures and errors are recorded as exception events and
+// properties as attributes.
+func JUnit(ctx context.Context, tracer trace.Tracer, r io.Reader, reportTime time.Time) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	reportTime = reportEnd(reportTime)
+
+	root, err := junitRootElement(data)
+	if err != nil {
+		return err
+	}
+
+	var suites []junitTestSuite
+	switch root {
+	case "testsuites":
+		var doc junitTestSuites
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		suites = doc.Suites
+	case "testsuite":
+		var suite junitTestSuite
+		if err := xml.Unmarshal(data, &suite); err != nil {
+			return err
+		}
+		suites = []junitTestSuite{suite}
+	default:
+		return fmt.Errorf("unexpected root element %s in JUnit report", root)
+	}
+
+	for _, suite := range suites {
+		recordJUnitSuite(ctx, tracer, suite, time.Time{}, reportTime)
+	}
+	return nil
+}
+
+// junitRootElement returns the name of the first element of the XML document.
+func junitRootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", errors.New("empty JUnit report")
+			}
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// recordJUnitSuite records the suite, its test cases and any nested suites, returning the end time of the suite.
+// Nested suites without a timestamp start at parentCursor.
+func recordJUnitSuite(ctx context.Context, tracer trace.Tracer, suite junitTestSuite, parentCursor time.Time, reportTime time.Time) time.Time {
+	duration := junitDuration(suite.Time)
+	if duration == 0 {
+		for _, c := range suite.Cases {
+			duration += junitDuration(c.Time)
+		}
+	}
+
+	start, ok := junitTimestamp(suite.Timestamp)
+	if !ok {
+		if parentCursor.IsZero() {
+			start = reportTime.Add(-duration)
+		} else {
+			start = parentCursor
+		}
+	}
+
+	attrs := []attribute.KeyValue{TestSuiteNameKey.String(suite.Name)}
+	if suite.Hostname != "" {
+		attrs = append(attrs, attribute.String("host.name", suite.Hostname))
+	}
+	attrs = append(attrs, junitPropertyAttributes(suite.Properties)...)
+
+	suiteCtx, span := tracer.Start(ctx, suite.Name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+
+	failed := false
+	cursor := start
+	for _, c := range suite.Cases {
+		if recordJUnitCase(suiteCtx, tracer, suite.Name, c, cursor) {
+			failed = true
+		}
+		cursor = cursor.Add(junitDuration(c.Time))
+	}
+	for _, nested := range suite.Suites {
+		cursor = recordJUnitSuite(suiteCtx, tracer, nested, cursor, reportTime)
+	}
+
+	end := start.Add(duration)
+	if cursor.After(end) {
+		end = cursor
+	}
+	if failed {
+		setResult(span, ResultFail, fmt.Sprintf("%s failed", suite.Name))
+	}
+	span.End(trace.WithTimestamp(end))
+	return end
+}
+
+// recordJUnitCase records a span for the test case starting at start, returning whether it failed.
+func recordJUnitCase(ctx context.Context, tracer trace.Tracer, suiteName string, c junitTestCase, start time.Time) bool {
+	end := start.Add(junitDuration(c.Time))
+
+	attrs := []attribute.KeyValue{
+		TestSuiteNameKey.String(suiteName),
+		TestCaseNameKey.String(c.Name),
+	}
+	if c.ClassName != "" {
+		attrs = append(attrs, attribute.String("code.namespace", c.ClassName))
+	}
+	if c.File != "" {
+		attrs = append(attrs, attribute.String("code.filepath", c.File))
+	}
+	attrs = append(attrs, junitPropertyAttributes(c.Properties)...)
+
+	_, span := tracer.Start(ctx, c.Name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	defer span.End(trace.WithTimestamp(end))
+
+	for _, failure := range c.Failures {
+		recordException(span, failure.Type, failure.Message, strings.TrimSpace(failure.Text), end)
+	}
+	for _, e := range c.Errors {
+		recordException(span, e.Type, e.Message, strings.TrimSpace(e.Text), end)
+	}
+
+	switch {
+	case len(c.Errors) > 0:
+		setResult(span, ResultError, junitFailureDescription(c.Errors[0], c.Name))
+	case len(c.Failures) > 0:
+		setResult(span, ResultFail, junitFailureDescription(c.Failures[0], c.Name))
+	case c.Skipped != nil:
+		setResult(span, ResultSkip, "")
+	default:
+		setResult(span, ResultPass, "")
+	}
+
+	return len(c.Errors) > 0 || len(c.Failures) > 0
+}
+
+func junitFailureDescription(failure junitFailure, caseName string) string {
+	if failure.Message != "" {
+		return failure.Message
+	}
+	return fmt.Sprintf("%s failed", caseName)
+}
+
+func junitPropertyAttributes(properties []junitProperty) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, p := range properties {
+		if p.Name != "" {
+			attrs = append(attrs, attribute.String("junit.property."+p.Name, p.Value))
+		}
+	}
+	return attrs
+}
+
+// junitDuration parses a duration in seconds, tolerating the thousands separators some reporters write.
+func junitDuration(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func junitTimestamp(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range junitTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,74 @@
+package ingest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"strings"
+	"testing"
+	"time"
+)
+
+const junitReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="pkg.FooTest" timestamp="2024-01-01T10:00:00Z" time="3.5" hostname="runner-1">
+    <properties>
+      <property name="java.version" value="21"/>
+    </properties>
+    <testcase name="passes" classname="pkg.FooTest" time="1.5"/>
+    <testcase name="fails" classname="pkg.FooTest" time="2">
+      <failure message="expected 1 but was 2" type="AssertionError">at pkg.FooTest.fails(FooTest.java:10)</failure>
+    </testcase>
+    <testcase name="skipped" classname="pkg.FooTest" time="0">
+      <skipped/>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+
+func TestJUnit(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+
+	err := JUnit(ctx, tracer, strings.NewReader(junitReport), time.Now())
+	assert.Nil(t, err)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans, 4)
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	suite := spans["pkg.FooTest"]
+	assert.True(t, start.Equal(suite.StartTime()))
+	assert.True(t, start.Add(3500*time.Millisecond).Equal(suite.EndTime()))
+	assert.Equal(t, codes.Error, suite.Status().Code)
+	assert.Equal(t, "21", attributeValue(suite, "junit.property.java.version"))
+	assert.Equal(t, "runner-1", attributeValue(suite, "host.name"))
+
+	passes := spans["passes"]
+	assert.Equal(t, suite.SpanContext().SpanID(), passes.Parent().SpanID())
+	assert.Equal(t, ResultPass, attributeValue(passes, TestCaseResultStatusKey))
+
+	fails := spans["fails"]
+	assert.True(t, start.Add(1500*time.Millisecond).Equal(fails.StartTime()))
+	assert.True(t, start.Add(3500*time.Millisecond).Equal(fails.EndTime()))
+	assert.Equal(t, codes.Error, fails.Status().Code)
+	assert.Equal(t, "expected 1 but was 2", fails.Status().Description)
+	if assert.Len(t, fails.Events(), 1) {
+		event := fails.Events()[0]
+		assert.Equal(t, semconv.ExceptionEventName, event.Name)
+		assert.Contains(t, event.Attributes, semconv.ExceptionStacktrace("at pkg.FooTest.fails(FooTest.java:10)"))
+	}
+
+	assert.Equal(t, ResultSkip, attributeValue(spans["skipped"], TestCaseResultStatusKey))
+}
+
+func TestJUnitSingleSuiteWithoutTimestamp(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+	reportTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	err := JUnit(ctx, tracer, strings.NewReader(`<testsuite name="suite"><testcase name="a" time="1,000.5"/></testsuite>`), reportTime)
+	assert.Nil(t, err)
+
+	suite := spansByName(recorder)["suite"]
+	assert.Equal(t, reportTime.Add(-1000500*time.Millisecond), suite.StartTime())
+	assert.Equal(t, reportTime, suite.EndTime())
+}
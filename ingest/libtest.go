@@ -0,0 +1,197 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"strings"
+	"time"
+)
+
+// libtestEvent is a line of the libtest JSON format written by `cargo test -- -Z unstable-options --format json` and
+// by nextest's libtest-json message format.
+type libtestEvent struct {
+	Type     string          `json:"type"`
+	Event    string          `json:"event"`
+	Name     string          `json:"name"`
+	ExecTime *float64        `json:"exec_time"`
+	Stdout   string          `json:"stdout"`
+	Message  string          `json:"message"`
+	Nextest  *libtestNextest `json:"nextest"`
+}
+
+type libtestNextest struct {
+	Crate      string `json:"crate"`
+	TestBinary string `json:"test_binary"`
+	Kind       string `json:"kind"`
+}
+
+type libtestSuite struct {
+	name    string
+	nextest *libtestNextest
+	begin   time.Time
+	end     *libtestEvent
+	arrival time.Time
+	tests   []*libtestTest
+}
+
+type libtestTest struct {
+	name    string
+	begin   time.Time
+	end     *libtestEvent
+	arrival time.Time
+}
+
+// Libtest reads a libtest JSON stream from r and records a span for each test suite, which is a test binary, with a
+// child span for each test. Tests start and end when their events were read, shifted to end at reportTime for report
+// files, and use exec_time for their duration when the stream includes it.
+func Libtest(ctx context.Context, tracer trace.Tracer, r io.Reader, reportTime time.Time) error {
+	var suites []*libtestSuite
+	var suite *libtestSuite
+	var last time.Time
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var event libtestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Type == "" {
+			continue
+		}
+		now := time.Now()
+		last = now
+
+		if suite == nil || (event.Type == "suite" && event.Event == "started") {
+			suite = &libtestSuite{name: "libtest", begin: now, arrival: now}
+			suites = append(suites, suite)
+		}
+
+		switch event.Type {
+		case "suite":
+			if event.Nextest != nil {
+				suite.nextest = event.Nextest
+				suite.name = event.Nextest.TestBinary
+			}
+			if event.Event != "started" {
+				suite.end = &event
+				suite.arrival = now
+				suite = nil
+			}
+		case "test":
+			var test *libtestTest
+			for _, t := range suite.tests {
+				if t.name == event.Name && t.end == nil {
+					test = t
+				}
+			}
+			if test == nil {
+				test = &libtestTest{name: event.Name, begin: now}
+				suite.tests = append(suite.tests, test)
+			}
+			if event.Event != "started" {
+				test.end = &event
+				test.arrival = now
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	offset := arrivalOffset(reportTime, last)
+	for _, s := range suites {
+		if s.end == nil {
+			s.arrival = last
+		}
+		recordLibtestSuite(ctx, tracer, s, offset)
+	}
+	return nil
+}
+
+func recordLibtestSuite(ctx context.Context, tracer trace.Tracer, suite *libtestSuite, offset time.Duration) {
+	attrs := []attribute.KeyValue{TestSuiteNameKey.String(suite.name)}
+	if suite.nextest != nil {
+		attrs = append(attrs,
+			attribute.String("nextest.crate", suite.nextest.Crate),
+			attribute.String("nextest.test_binary", suite.nextest.TestBinary),
+			attribute.String("nextest.kind", suite.nextest.Kind),
+		)
+	}
+
+	end := suite.arrival.Add(offset)
+	start := suite.begin.Add(offset)
+	if suite.end != nil && suite.end.ExecTime != nil {
+		start = end.Add(-secondsToDuration(*suite.end.ExecTime))
+	}
+
+	suiteCtx, span := tracer.Start(ctx, suite.name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	for _, test := range suite.tests {
+		recordLibtestTest(suiteCtx, tracer, suite.name, test, offset)
+	}
+
+	switch {
+	case suite.end == nil:
+		setResult(span, ResultFail, "no suite result reported")
+	case suite.end.Event == "failed":
+		setResult(span, ResultFail, fmt.Sprintf("%s failed", suite.name))
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+func recordLibtestTest(ctx context.Context, tracer trace.Tracer, suiteName string, test *libtestTest, offset time.Duration) {
+	// nextest prefixes test names with the binary id and a $ separator
+	name := test.name
+	if _, testName, found := strings.Cut(name, "$"); found {
+		name = testName
+	}
+
+	arrival := test.arrival
+	if test.end == nil {
+		arrival = test.begin
+	}
+	end := arrival.Add(offset)
+	start := test.begin.Add(offset)
+	if test.end != nil && test.end.ExecTime != nil {
+		start = end.Add(-secondsToDuration(*test.end.ExecTime))
+	}
+
+	_, span := tracer.Start(ctx, name,
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			TestSuiteNameKey.String(suiteName),
+			TestCaseNameKey.String(name),
+		),
+	)
+	defer span.End(trace.WithTimestamp(end))
+
+	if test.end == nil {
+		setResult(span, ResultFail, "no test result reported")
+		return
+	}
+
+	switch test.end.Event {
+	case "ok":
+		setResult(span, ResultPass, "")
+	case "ignored":
+		setResult(span, ResultSkip, "")
+		if test.end.Message != "" {
+			span.SetAttributes(TestCaseResultReasonKey.String(test.end.Message))
+		}
+	default:
+		message := test.end.Message
+		if message == "" {
+			message = fmt.Sprintf("%s %s", name, test.end.Event)
+		}
+		if test.end.Stdout != "" {
+			recordException(span, "", message, test.end.Stdout, end)
+		}
+		setResult(span, ResultFail, message)
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,48 @@
+package ingest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const libtestStream = `{ "type": "suite", "event": "started", "test_count": 3, "nextest": { "crate": "foo", "test_binary": "foo", "kind": "lib" } }
+{ "type": "test", "event": "started", "name": "foo$tests::passes" }
+{ "type": "test", "event": "started", "name": "foo$tests::fails" }
+{ "type": "test", "name": "foo$tests::passes", "event": "ok", "exec_time": 0.5 }
+{ "type": "test", "name": "foo$tests::fails", "event": "failed", "exec_time": 1.25, "stdout": "thread 'tests::fails' panicked at src/lib.rs:10:9" }
+{ "type": "test", "event": "ignored", "name": "foo$tests::ignored", "message": "slow" }
+{ "type": "suite", "event": "failed", "passed": 1, "failed": 1, "ignored": 1, "exec_time": 2, "nextest": { "crate": "foo", "test_binary": "foo", "kind": "lib" } }
+`
+
+func TestLibtest(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+	reportTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	err := Libtest(ctx, tracer, strings.NewReader(libtestStream), reportTime)
+	assert.Nil(t, err)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans, 4)
+
+	suite := spans["foo"]
+	assert.Equal(t, codes.Error, suite.Status().Code)
+	assert.WithinDuration(t, reportTime, suite.EndTime(), 0)
+	assert.Equal(t, 2*time.Second, suite.EndTime().Sub(suite.StartTime()))
+	assert.Equal(t, "lib", attributeValue(suite, "nextest.kind"))
+
+	passes := spans["tests::passes"]
+	assert.Equal(t, suite.SpanContext().SpanID(), passes.Parent().SpanID())
+	assert.Equal(t, ResultPass, attributeValue(passes, TestCaseResultStatusKey))
+	assert.Equal(t, 500*time.Millisecond, passes.EndTime().Sub(passes.StartTime()))
+
+	fails := spans["tests::fails"]
+	assert.Equal(t, codes.Error, fails.Status().Code)
+	assert.Len(t, fails.Events(), 1)
+
+	ignored := spans["tests::ignored"]
+	assert.Equal(t, ResultSkip, attributeValue(ignored, TestCaseResultStatusKey))
+	assert.Equal(t, "slow", attributeValue(ignored, TestCaseResultReasonKey))
+}
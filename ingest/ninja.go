@@ -0,0 +1,91 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ninjaEdge is a build edge of a .ninja_log, which can produce several outputs.
+type ninjaEdge struct {
+	start, end  int64
+	outputs     []string
+	commandHash string
+}
+
+// NinjaLog reads a .ninja_log file from r and records a span for each build edge of the most recent build in the log.
+// Log timestamps are milliseconds relative to the start of the build, so the build is placed to end at reportTime,
+// the modification time of the log, or when reading finished for streamed logs.
+func NinjaLog(ctx context.Context, tracer trace.Tracer, r io.Reader, reportTime time.Time) error {
+	var edges []*ninjaEdge
+	byKey := map[string]*ninjaEdge{}
+	var lastEnd int64
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 5 {
+			return fmt.Errorf("malformed ninja log line: %s", line)
+		}
+		start, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		end, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		// The log keeps entries of earlier builds; an entry ending before the previous one starts a new build
+		if end < lastEnd {
+			edges = nil
+			byKey = map[string]*ninjaEdge{}
+		}
+		lastEnd = end
+
+		// Edges with several outputs are logged once per output with the same times and command hash
+		key := fmt.Sprintf("%d/%d/%s", start, end, fields[4])
+		if edge, ok := byKey[key]; ok {
+			edge.outputs = append(edge.outputs, fields[3])
+			continue
+		}
+		edge := &ninjaEdge{start: start, end: end, outputs: []string{fields[3]}, commandHash: fields[4]}
+		byKey[key] = edge
+		edges = append(edges, edge)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	var buildEnd int64
+	for _, edge := range edges {
+		if edge.end > buildEnd {
+			buildEnd = edge.end
+		}
+	}
+	origin := reportEnd(reportTime).Add(-time.Duration(buildEnd) * time.Millisecond)
+
+	for _, edge := range edges {
+		_, span := tracer.Start(ctx, edge.outputs[0],
+			trace.WithTimestamp(origin.Add(time.Duration(edge.start)*time.Millisecond)),
+			trace.WithAttributes(
+				attribute.StringSlice("ninja.outputs", edge.outputs),
+				attribute.String("ninja.command_hash", edge.commandHash),
+			),
+		)
+		span.End(trace.WithTimestamp(origin.Add(time.Duration(edge.end) * time.Millisecond)))
+	}
+
+	return nil
+}
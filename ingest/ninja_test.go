@@ -0,0 +1,35 @@
+package ingest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+const ninjaLog = `# ninja log v5
+0	500	0	old.o	aaaa
+10	200	0	foo.o	1111
+10	200	0	foo.d	1111
+15	900	0	bar.o	2222
+900	1000	0	app	3333
+`
+
+func TestNinjaLog(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+	reportTime := time.Date(2024, 1, 1, 10, 0, 1, 0, time.UTC)
+
+	err := NinjaLog(ctx, tracer, strings.NewReader(ninjaLog), reportTime)
+	assert.Nil(t, err)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans, 3)
+	assert.NotContains(t, spans, "old.o")
+
+	foo := spans["foo.o"]
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, int(10*time.Millisecond), time.UTC), foo.StartTime())
+	assert.Equal(t, 190*time.Millisecond, foo.EndTime().Sub(foo.StartTime()))
+	assert.Equal(t, "[foo.o foo.d]", attributeValue(foo, "ninja.outputs"))
+
+	assert.Equal(t, reportTime, spans["app"].EndTime())
+}
@@ -0,0 +1,182 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	tapTestPointPattern = regexp.MustCompile(`^(not )?ok\b\s*(\d+)?\s*(?:-\s*)?(.*)$`)
+	tapDirectivePattern = regexp.MustCompile(`(?i)\s+#\s*(skip|todo)\b\s*(.*)$`)
+)
+
+// tapTest is a test point of a TAP stream along with its subtests.
+type tapTest struct {
+	name      string
+	ok        bool
+	directive string
+	reason    string
+	yaml      []string
+	duration  time.Duration
+	begin     time.Time
+	arrival   time.Time
+	subtests  []*tapTest
+}
+
+// TAP reads a TAP version 13 or 14 stream from r and records a span for each test point, with TAP 14 subtests nested
+// under their parent test. Tests end at the time their result was read, shifted to end at reportTime for report
+// files, and start at the previous result unless the YAML diagnostics contain a duration_ms. A stream which bails out
+// returns an error once the tests read so far have been recorded.
+func TAP(ctx context.Context, tracer trace.Tracer, r io.Reader, reportTime time.Time) error {
+	// levels holds the test points read at each subtest depth which have not been adopted by a parent yet
+	var levels [][]*tapTest
+	var last *tapTest
+	var inYAML bool
+	var yamlIndent int
+	var bailOut string
+
+	previous := time.Now()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		now := time.Now()
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		depth := indent / 4
+
+		if inYAML {
+			if trimmed == "..." {
+				inYAML = false
+			} else {
+				last.yaml = append(last.yaml, line[min(indent, yamlIndent):])
+			}
+			continue
+		}
+		if trimmed == "---" && last != nil {
+			inYAML = true
+			yamlIndent = indent
+			continue
+		}
+		if strings.HasPrefix(trimmed, "Bail out!") {
+			bailOut = strings.TrimSpace(strings.TrimPrefix(trimmed, "Bail out!"))
+			break
+		}
+
+		match := tapTestPointPattern.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+
+		test := &tapTest{ok: match[1] == "", begin: previous, arrival: now}
+		description := match[3]
+		if directive := tapDirectivePattern.FindStringSubmatchIndex(description); directive != nil {
+			test.directive = strings.ToLower(description[directive[2]:directive[3]])
+			test.reason = description[directive[4]:directive[5]]
+			description = description[:directive[0]]
+		}
+		test.name = strings.TrimSpace(description)
+		if test.name == "" {
+			test.name = "test " + match[2]
+		}
+
+		for len(levels) <= depth+1 {
+			levels = append(levels, nil)
+		}
+		test.subtests = levels[depth+1]
+		if len(test.subtests) > 0 && test.subtests[0].begin.Before(test.begin) {
+			test.begin = test.subtests[0].begin
+		}
+		levels = levels[:depth+1]
+		levels[depth] = append(levels[depth], test)
+
+		last = test
+		previous = now
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// Subtests without a closing parent test point are recorded at the top level
+	var tests []*tapTest
+	for _, level := range levels {
+		tests = append(tests, level...)
+	}
+
+	offset := arrivalOffset(reportTime, previous)
+	for _, test := range tests {
+		recordTAPTest(ctx, tracer, test, offset)
+	}
+
+	if bailOut != "" {
+		return fmt.Errorf("TAP stream bailed out: %s", bailOut)
+	}
+	return nil
+}
+
+func recordTAPTest(ctx context.Context, tracer trace.Tracer, test *tapTest, offset time.Duration) {
+	diagnostics := tapDiagnostics(test.yaml)
+
+	end := test.arrival.Add(offset)
+	start := test.begin.Add(offset)
+	if value, ok := diagnostics["duration_ms"]; ok {
+		if ms, err := strconv.ParseFloat(value, 64); err == nil {
+			start = end.Add(-time.Duration(ms * float64(time.Millisecond)))
+		}
+	}
+
+	testCtx, span := tracer.Start(ctx, test.name,
+		trace.WithTimestamp(start),
+		trace.WithAttributes(TestCaseNameKey.String(test.name)),
+	)
+	for _, subtest := range test.subtests {
+		recordTAPTest(testCtx, tracer, subtest, offset)
+	}
+
+	switch {
+	case test.directive != "":
+		// Failures of TODO tests are expected and do not fail the run
+		setResult(span, ResultSkip, "")
+		if test.reason != "" {
+			span.SetAttributes(TestCaseResultReasonKey.String(test.reason))
+		}
+	case test.ok:
+		setResult(span, ResultPass, "")
+	default:
+		message := diagnostics["message"]
+		if message == "" {
+			message = fmt.Sprintf("%s failed", test.name)
+		}
+		if len(test.yaml) > 0 {
+			recordException(span, "", message, strings.Join(test.yaml, "\n"), end)
+		}
+		setResult(span, ResultFail, message)
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
+
+// tapDiagnostics extracts the top level scalar values of a YAML diagnostics block.
+func tapDiagnostics(lines []string) map[string]string {
+	diagnostics := map[string]string{}
+	for _, line := range lines {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, "'")
+		}
+		diagnostics[strings.TrimSpace(key)] = value
+	}
+	return diagnostics
+}
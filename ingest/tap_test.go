@@ -0,0 +1,61 @@
+package ingest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const tapStream = `TAP version 14
+1..4
+ok 1 - passes
+not ok 2 - fails
+  ---
+  message: 'expected true'
+  duration_ms: 250
+  at:
+    line: 10
+  ...
+ok 3 - skipped # SKIP not on linux
+# Subtest: group
+    ok 1 - inner
+    1..1
+ok 4 - group
+`
+
+func TestTAP(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+	reportTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	err := TAP(ctx, tracer, strings.NewReader(tapStream), reportTime)
+	assert.Nil(t, err)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans, 5)
+
+	assert.Equal(t, ResultPass, attributeValue(spans["passes"], TestCaseResultStatusKey))
+
+	fails := spans["fails"]
+	assert.Equal(t, codes.Error, fails.Status().Code)
+	assert.Equal(t, "expected true", fails.Status().Description)
+	assert.Equal(t, 250*time.Millisecond, fails.EndTime().Sub(fails.StartTime()))
+	assert.Len(t, fails.Events(), 1)
+
+	skipped := spans["skipped"]
+	assert.Equal(t, ResultSkip, attributeValue(skipped, TestCaseResultStatusKey))
+	assert.Equal(t, "not on linux", attributeValue(skipped, TestCaseResultReasonKey))
+
+	group := spans["group"]
+	assert.Equal(t, group.SpanContext().SpanID(), spans["inner"].Parent().SpanID())
+	assert.WithinDuration(t, reportTime, group.EndTime(), 0)
+}
+
+func TestTAPBailOut(t *testing.T) {
+	recorder, tracer, ctx := newTestTracer()
+
+	err := TAP(ctx, tracer, strings.NewReader("TAP version 13\nok 1 - first\nBail out! database unavailable\nok 2 - second\n"), time.Time{})
+	assert.EqualError(t, err, "TAP stream bailed out: database unavailable")
+	assert.Len(t, recorder.Ended(), 1)
+}
@@ -0,0 +1,231 @@
+// Package procwatch observes the descendants of a process by polling the operating system's process table and
+// converts the processes it saw into spans once the command has finished.
+package procwatch
+
+import (
+	"context"
+	"github.com/nextrevision/traci/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultInterval  = 100 * time.Millisecond
+	DefaultThreshold = 500 * time.Millisecond
+)
+
+// Process is a descendant process observed while watching a process tree.
+type Process struct {
+	PID     int
+	PPID    int
+	Command []string
+	Start   time.Time
+	End     time.Time
+}
+
+// procInfo is a single process entry read from the process table.
+type procInfo struct {
+	ppid    int
+	start   time.Time
+	command []string
+}
+
+// procKey identifies a process across polls, guarding against PID reuse.
+type procKey struct {
+	pid   int
+	start time.Time
+}
+
+// Watcher polls the process table at a fixed interval and records every descendant of the root process.
+type Watcher struct {
+	root     int
+	interval time.Duration
+
+	mu        sync.Mutex
+	processes map[procKey]*Process
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// NewWatcher creates a Watcher for the descendants of the process with the given PID.
+func NewWatcher(root int, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Watcher{
+		root:      root,
+		interval:  interval,
+		processes: map[procKey]*Process{},
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// Start begins polling in the background until Stop is called.
+func (w *Watcher) Start() {
+	go func() {
+		defer close(w.stopped)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.poll(); err != nil {
+				slog.Debug(err.Error())
+				return
+			}
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends polling and returns every process observed. Processes still running are given the current time as their
+// end time.
+func (w *Watcher) Stop() []Process {
+	close(w.stop)
+	<-w.stopped
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	processes := make([]Process, 0, len(w.processes))
+	for _, p := range w.processes {
+		if p.End.IsZero() {
+			p.End = now
+		}
+		processes = append(processes, *p)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		if processes[i].Start.Equal(processes[j].Start) {
+			return processes[i].PID < processes[j].PID
+		}
+		return processes[i].Start.Before(processes[j].Start)
+	})
+	return processes
+}
+
+// poll reads the process table once, tracking new descendants of the root and marking vanished processes as ended.
+func (w *Watcher) poll() error {
+	table, err := readProcessTable()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Close out tracked processes which have exited or whose PID has been reused
+	tracked := map[int]bool{w.root: true}
+	for key, p := range w.processes {
+		if !p.End.IsZero() {
+			continue
+		}
+		if info, ok := table[key.pid]; ok && info.start.Equal(key.start) {
+			tracked[key.pid] = true
+		} else {
+			p.End = now
+		}
+	}
+
+	// Walk the tree from the root and any tracked processes, which keeps following children reparented to init
+	children := map[int][]int{}
+	for pid, info := range table {
+		children[info.ppid] = append(children[info.ppid], pid)
+	}
+	queue := make([]int, 0, len(tracked))
+	for pid := range tracked {
+		queue = append(queue, pid)
+	}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		for _, child := range children[pid] {
+			info := table[child]
+			key := procKey{pid: child, start: info.start}
+			if _, ok := w.processes[key]; !ok {
+				w.processes[key] = &Process{
+					PID:     child,
+					PPID:    info.ppid,
+					Command: info.command,
+					Start:   info.start,
+				}
+			}
+			if !tracked[child] {
+				tracked[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Emit creates a span for each process which lived at least as long as threshold. Spans are nested according to the
+// process tree; processes below the threshold are skipped and their descendants attached to the closest emitted
+// ancestor, or to the span in ctx.
+func Emit(ctx context.Context, tracer trace.Tracer, processes []Process, threshold time.Duration) {
+	byPID := map[int]*Process{}
+	for i := range processes {
+		byPID[processes[i].PID] = &processes[i]
+	}
+
+	contexts := map[int]context.Context{}
+	var contextFor func(p *Process) context.Context
+	contextFor = func(p *Process) context.Context {
+		if c, ok := contexts[p.PID]; ok {
+			return c
+		}
+
+		parentCtx := ctx
+		if parent, ok := byPID[p.PPID]; ok && parent != p && !parent.Start.After(p.Start) {
+			parentCtx = contextFor(parent)
+		}
+
+		c := parentCtx
+		if p.End.Sub(p.Start) >= threshold {
+			var span trace.Span
+			c, span = tracer.Start(parentCtx, spanName(p), trace.WithTimestamp(p.Start), trace.WithAttributes(attributes(p)...))
+			span.End(trace.WithTimestamp(p.End))
+		}
+		contexts[p.PID] = c
+		return c
+	}
+
+	for i := range processes {
+		contextFor(&processes[i])
+	}
+}
+
+func spanName(p *Process) string {
+	if len(p.Command) == 0 || p.Command[0] == "" {
+		return "process"
+	}
+	return filepath.Base(p.Command[0])
+}
+
+func attributes(p *Process) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.ProcessPID(p.PID),
+		semconv.ProcessParentPID(p.PPID),
+	}
+	if len(p.Command) > 0 {
+		attrs = append(attrs,
+			semconv.ProcessExecutableName(filepath.Base(p.Command[0])),
+			semconv.ProcessCommandLine(strings.Join(tracing.RedactArgs(p.Command), " ")),
+		)
+	}
+	return attrs
+}
@@ -0,0 +1,108 @@
+//go:build linux
+
+package procwatch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockTicks is the kernel's USER_HZ used for process start times in /proc/<pid>/stat, which is 100 on all
+// mainstream architectures.
+const clockTicks = 100
+
+// readProcessTable reads the ppid, start time and command line of every process from /proc.
+func readProcessTable() (map[int]procInfo, error) {
+	bootTime, err := readBootTime()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	table := map[int]procInfo{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		// Processes can exit between listing and reading; skip any that have disappeared
+		ppid, startTicks, err := readStat(pid)
+		if err != nil {
+			continue
+		}
+		cmdline, _ := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+
+		table[pid] = procInfo{
+			ppid:    ppid,
+			start:   bootTime.Add(time.Duration(startTicks) * time.Second / clockTicks),
+			command: splitCmdline(cmdline),
+		}
+	}
+
+	return table, nil
+}
+
+// readStat returns the parent PID and start time, in clock ticks since boot, from /proc/<pid>/stat.
+func readStat(pid int) (int, uint64, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// The command name is wrapped in parentheses and may itself contain spaces or parentheses
+	end := bytes.LastIndexByte(data, ')')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	// fields[0] is the state (field 3 in proc(5)), so ppid is fields[1] and starttime (field 22) is fields[19]
+	if len(fields) < 20 {
+		return 0, 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	start, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ppid, start, nil
+}
+
+// readBootTime estimates the system boot time from /proc/uptime, which has a finer resolution than the btime entry
+// of /proc/stat. It is read once so start times stay stable between polls.
+var readBootTime = sync.OnceValues(func() (time.Time, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return time.Time{}, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return time.Time{}, errors.New("malformed /proc/uptime")
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-time.Duration(uptime * float64(time.Second))), nil
+})
+
+func splitCmdline(cmdline []byte) []string {
+	cmdline = bytes.TrimRight(cmdline, "\x00")
+	if len(cmdline) == 0 {
+		return nil
+	}
+	return strings.Split(string(cmdline), "\x00")
+}
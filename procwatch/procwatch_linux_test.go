@@ -0,0 +1,32 @@
+//go:build linux
+
+package procwatch
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	cmd := exec.Command("/bin/sh", "-c", "sleep 0.3; true")
+	assert.Nil(t, cmd.Start())
+
+	watcher := NewWatcher(cmd.Process.Pid, 20*time.Millisecond)
+	watcher.Start()
+	assert.Nil(t, cmd.Wait())
+	processes := watcher.Stop()
+
+	var sleep *Process
+	for i := range processes {
+		if len(processes[i].Command) > 0 && processes[i].Command[0] == "sleep" {
+			sleep = &processes[i]
+		}
+	}
+	if assert.NotNil(t, sleep, "expected sleep in %v", processes) {
+		assert.Equal(t, cmd.Process.Pid, sleep.PPID)
+		assert.Equal(t, []string{"sleep", "0.3"}, sleep.Command)
+		assert.GreaterOrEqual(t, sleep.End.Sub(sleep.Start), 200*time.Millisecond)
+	}
+}
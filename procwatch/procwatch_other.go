@@ -0,0 +1,10 @@
+//go:build !linux
+
+package procwatch
+
+import "errors"
+
+// readProcessTable is only implemented on Linux, where the process tree can be read from /proc.
+func readProcessTable() (map[int]procInfo, error) {
+	return nil, errors.New("process tree tracking is only supported on linux")
+}
@@ -0,0 +1,45 @@
+package procwatch
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+func TestEmit(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	processes := []Process{
+		{PID: 10, PPID: 1, Command: []string{"/usr/bin/make", "build"}, Start: start, End: start.Add(5 * time.Second)},
+		{PID: 11, PPID: 10, Command: []string{"/bin/sh", "-c", "cc"}, Start: start.Add(time.Second), End: start.Add(time.Second + 10*time.Millisecond)},
+		{PID: 12, PPID: 11, Command: []string{"cc", "--token=abc", "main.c"}, Start: start.Add(time.Second), End: start.Add(3 * time.Second)},
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	ctx, root := tracer.Start(context.Background(), "root")
+
+	Emit(ctx, tracer, processes, 500*time.Millisecond)
+	root.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	assert.Len(t, spans, 3)
+	assert.NotContains(t, spans, "sh")
+	assert.Equal(t, root.SpanContext().SpanID(), spans["make"].Parent().SpanID())
+	// The short-lived shell is skipped, so the compiler is attached to make
+	assert.Equal(t, spans["make"].SpanContext().SpanID(), spans["cc"].Parent().SpanID())
+	assert.Equal(t, start.Add(time.Second), spans["cc"].StartTime())
+	assert.Equal(t, start.Add(3*time.Second), spans["cc"].EndTime())
+
+	for _, attr := range spans["cc"].Attributes() {
+		if attr.Key == "process.command_line" {
+			assert.Equal(t, "cc --token=REDACTED main.c", attr.Value.AsString())
+		}
+	}
+}
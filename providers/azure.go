@@ -0,0 +1,153 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type AzurePipelines struct{}
+
+func (a AzurePipelines) GetCIName() string {
+	return "Azure-Pipelines"
+}
+
+func (a AzurePipelines) GetPipelineID() string {
+	return os.Getenv("BUILD_BUILDID")
+}
+
+// GetJobID returns the ID of the job suffixed by its attempt, as re-running a failed job keeps its ID.
+func (a AzurePipelines) GetJobID() string {
+	if attempt := os.Getenv("SYSTEM_JOBATTEMPT"); attempt != "" {
+		return fmt.Sprintf("%s-%s", os.Getenv("SYSTEM_JOBID"), attempt)
+	}
+	return os.Getenv("SYSTEM_JOBID")
+}
+
+func (a AzurePipelines) GetServiceName() string {
+	return strings.ToLower(a.GetCIName())
+}
+
+func (a AzurePipelines) GetSpanName() string {
+	return os.Getenv("SYSTEM_JOBDISPLAYNAME")
+}
+
+func (a AzurePipelines) GetAttributes() map[string]string {
+	// See https://learn.microsoft.com/en-us/azure/devops/pipelines/build/variables
+	return map[string]string{
+		"azure.build.id":        os.Getenv("BUILD_BUILDID"),
+		"azure.build.number":    os.Getenv("BUILD_BUILDNUMBER"),
+		"azure.definition.name": os.Getenv("BUILD_DEFINITIONNAME"),
+		"azure.stage.name":      os.Getenv("SYSTEM_STAGENAME"),
+		"azure.job.id":          os.Getenv("SYSTEM_JOBID"),
+		"azure.job.name":        os.Getenv("SYSTEM_JOBDISPLAYNAME"),
+		"azure.job.attempt":     os.Getenv("SYSTEM_JOBATTEMPT"),
+		"azure.repo":            os.Getenv("BUILD_REPOSITORY_NAME"),
+		"azure.branch":          os.Getenv("BUILD_SOURCEBRANCH"),
+		"azure.sha":             os.Getenv("BUILD_SOURCEVERSION"),
+	}
+}
+
+func (a AzurePipelines) GetBasePipelineID() string {
+	return a.GetPipelineID()
+}
+
+// GetAttempt returns 1 as re-running failed jobs of Azure Pipelines keeps the build ID, so every attempt shares the
+// trace of the first one.
+func (a AzurePipelines) GetAttempt() int {
+	return 1
+}
+
+// GetUpstreamPipelineID returns the run ID of the pipeline resource which triggered this run.
+func (a AzurePipelines) GetUpstreamPipelineID() string {
+	alias := os.Getenv("RESOURCES_TRIGGERINGALIAS")
+	if os.Getenv("BUILD_REASON") != "ResourceTrigger" || alias == "" {
+		return ""
+	}
+	return os.Getenv(fmt.Sprintf("RESOURCES_PIPELINE_%s_RUNID", strings.ToUpper(alias)))
+}
+
+// GetStageID returns the ID of the stage, or the pipeline ID for pipelines without stages.
+func (a AzurePipelines) GetStageID() string {
+	stageID := os.Getenv("SYSTEM_STAGEID")
+	if stageID == "" {
+		return a.GetPipelineID()
+	}
+	return fmt.Sprintf("%s-%s", a.GetPipelineID(), stageID)
+}
+
+func (a AzurePipelines) GetStageName() string {
+	return os.Getenv("SYSTEM_STAGENAME")
+}
+
+func (a AzurePipelines) GetPipelineName() string {
+	return os.Getenv("BUILD_DEFINITIONNAME")
+}
+
+func (a AzurePipelines) GetJobName() string {
+	return os.Getenv("SYSTEM_JOBDISPLAYNAME")
+}
+
+func (a AzurePipelines) GetRunID() string {
+	return a.GetPipelineID()
+}
+
+func (a AzurePipelines) GetRunURL() string {
+	collectionURI := os.Getenv("SYSTEM_COLLECTIONURI")
+	if collectionURI == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s%s/_build/results?buildId=%s", collectionURI, os.Getenv("SYSTEM_TEAMPROJECT"), a.GetPipelineID())
+}
+
+func (a AzurePipelines) GetJobRunID() string {
+	return os.Getenv("SYSTEM_JOBID")
+}
+
+func (a AzurePipelines) GetJobURL() string {
+	runURL := a.GetRunURL()
+	if runURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s&view=logs&j=%s", runURL, os.Getenv("SYSTEM_JOBID"))
+}
+
+func (a AzurePipelines) GetActor() string {
+	return os.Getenv("BUILD_REQUESTEDFOR")
+}
+
+func (a AzurePipelines) GetTriggerEvent() string {
+	return os.Getenv("BUILD_REASON")
+}
+
+func (a AzurePipelines) GetRepositoryName() string {
+	return repositoryName(os.Getenv("BUILD_REPOSITORY_NAME"))
+}
+
+func (a AzurePipelines) GetRepositoryURL() string {
+	return os.Getenv("BUILD_REPOSITORY_URI")
+}
+
+func (a AzurePipelines) GetBranch() string {
+	// BUILD_SOURCEBRANCH is the merge ref of pull request builds
+	if branch := os.Getenv("SYSTEM_PULLREQUEST_SOURCEBRANCH"); branch != "" {
+		return strings.TrimPrefix(branch, "refs/heads/")
+	}
+	branch, found := strings.CutPrefix(os.Getenv("BUILD_SOURCEBRANCH"), "refs/heads/")
+	if !found {
+		return ""
+	}
+	return branch
+}
+
+func (a AzurePipelines) GetTag() string {
+	tag, found := strings.CutPrefix(os.Getenv("BUILD_SOURCEBRANCH"), "refs/tags/")
+	if !found {
+		return ""
+	}
+	return tag
+}
+
+func (a AzurePipelines) GetCommitSHA() string {
+	return os.Getenv("BUILD_SOURCEVERSION")
+}
@@ -38,3 +38,79 @@ func (b Bitbucket) GetAttributes() map[string]string {
 		"bitbucket.repo.uuid": os.Getenv("BITBUCKET_REPO_UUID"),
 	}
 }
+
+func (b Bitbucket) GetBasePipelineID() string {
+	return b.GetPipelineID()
+}
+
+func (b Bitbucket) GetAttempt() int {
+	return 1
+}
+
+// GetUpstreamPipelineID returns an empty string as Bitbucket doesn't expose the pipeline triggering this one.
+func (b Bitbucket) GetUpstreamPipelineID() string {
+	return ""
+}
+
+// GetStageID returns the pipeline ID as Bitbucket doesn't expose a stage variable to its steps, so the whole
+// pipeline is a single stage.
+func (b Bitbucket) GetStageID() string {
+	return b.GetPipelineID()
+}
+
+// GetStageName returns an empty string as Bitbucket doesn't expose a stage variable to its steps.
+func (b Bitbucket) GetStageName() string {
+	return ""
+}
+
+func (b Bitbucket) GetPipelineName() string {
+	return ""
+}
+
+func (b Bitbucket) GetJobName() string {
+	return ""
+}
+
+func (b Bitbucket) GetRunID() string {
+	return b.GetPipelineID()
+}
+
+func (b Bitbucket) GetRunURL() string {
+	return ""
+}
+
+func (b Bitbucket) GetJobRunID() string {
+	return b.GetJobID()
+}
+
+func (b Bitbucket) GetJobURL() string {
+	return ""
+}
+
+func (b Bitbucket) GetActor() string {
+	return ""
+}
+
+func (b Bitbucket) GetTriggerEvent() string {
+	return ""
+}
+
+func (b Bitbucket) GetRepositoryName() string {
+	return os.Getenv("BITBUCKET_REPO_SLUG")
+}
+
+func (b Bitbucket) GetRepositoryURL() string {
+	return os.Getenv("BITBUCKET_GIT_HTTP_ORIGIN")
+}
+
+func (b Bitbucket) GetBranch() string {
+	return os.Getenv("BITBUCKET_BRANCH")
+}
+
+func (b Bitbucket) GetTag() string {
+	return os.Getenv("BITBUCKET_TAG")
+}
+
+func (b Bitbucket) GetCommitSHA() string {
+	return os.Getenv("BITBUCKET_COMMIT")
+}
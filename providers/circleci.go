@@ -39,3 +39,79 @@ func (c CircleCI) GetAttributes() map[string]string {
 		"circleci.sha":             os.Getenv("CIRCLE_SHA1"),
 	}
 }
+
+func (c CircleCI) GetBasePipelineID() string {
+	return c.GetPipelineID()
+}
+
+func (c CircleCI) GetAttempt() int {
+	return 1
+}
+
+// GetUpstreamPipelineID returns an empty string as CircleCI doesn't expose the pipeline triggering this one.
+func (c CircleCI) GetUpstreamPipelineID() string {
+	return ""
+}
+
+// GetStageID returns the pipeline ID as CircleCI doesn't expose a stage variable to its jobs, so the whole pipeline is
+// a single stage.
+func (c CircleCI) GetStageID() string {
+	return c.GetPipelineID()
+}
+
+// GetStageName returns an empty string as CircleCI doesn't expose a stage variable to its jobs.
+func (c CircleCI) GetStageName() string {
+	return ""
+}
+
+func (c CircleCI) GetPipelineName() string {
+	return ""
+}
+
+func (c CircleCI) GetJobName() string {
+	return os.Getenv("CIRCLE_JOB")
+}
+
+func (c CircleCI) GetRunID() string {
+	return c.GetPipelineID()
+}
+
+func (c CircleCI) GetRunURL() string {
+	return ""
+}
+
+func (c CircleCI) GetJobRunID() string {
+	return c.GetJobID()
+}
+
+func (c CircleCI) GetJobURL() string {
+	return os.Getenv("CIRCLE_BUILD_URL")
+}
+
+func (c CircleCI) GetActor() string {
+	return os.Getenv("CIRCLE_USERNAME")
+}
+
+func (c CircleCI) GetTriggerEvent() string {
+	return ""
+}
+
+func (c CircleCI) GetRepositoryName() string {
+	return os.Getenv("CIRCLE_PROJECT_REPONAME")
+}
+
+func (c CircleCI) GetRepositoryURL() string {
+	return os.Getenv("CIRCLE_REPOSITORY_URL")
+}
+
+func (c CircleCI) GetBranch() string {
+	return os.Getenv("CIRCLE_BRANCH")
+}
+
+func (c CircleCI) GetTag() string {
+	return os.Getenv("CIRCLE_TAG")
+}
+
+func (c CircleCI) GetCommitSHA() string {
+	return os.Getenv("CIRCLE_SHA1")
+}
@@ -1,8 +1,10 @@
 package providers
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -13,7 +15,47 @@ func (g GitHubActions) GetCIName() string {
 }
 
 func (g GitHubActions) GetPipelineID() string {
-	return fmt.Sprintf("%s-%s-%s", os.Getenv("GITHUB_RUN_ID"), os.Getenv("GITHUB_RUN_NUMBER"), os.Getenv("GITHUB_RUN_ATTEMPT"))
+	return PipelineIDForAttempt(g, g.GetAttempt())
+}
+
+func (g GitHubActions) GetBasePipelineID() string {
+	return fmt.Sprintf("%s-%s", os.Getenv("GITHUB_RUN_ID"), os.Getenv("GITHUB_RUN_NUMBER"))
+}
+
+func (g GitHubActions) GetAttempt() int {
+	attempt, err := strconv.Atoi(os.Getenv("GITHUB_RUN_ATTEMPT"))
+	if err != nil || attempt < 1 {
+		return 1
+	}
+	return attempt
+}
+
+// GetUpstreamPipelineID returns the pipeline ID of the workflow run which triggered a workflow_run event, read from the
+// event payload.
+func (g GitHubActions) GetUpstreamPipelineID() string {
+	if os.Getenv("GITHUB_EVENT_NAME") != "workflow_run" {
+		return ""
+	}
+	data, err := os.ReadFile(os.Getenv("GITHUB_EVENT_PATH"))
+	if err != nil {
+		return ""
+	}
+
+	var event struct {
+		WorkflowRun struct {
+			ID         int64 `json:"id"`
+			RunNumber  int64 `json:"run_number"`
+			RunAttempt int64 `json:"run_attempt"`
+		} `json:"workflow_run"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil || event.WorkflowRun.ID == 0 {
+		return ""
+	}
+	run := event.WorkflowRun
+	if run.RunAttempt < 1 {
+		run.RunAttempt = 1
+	}
+	return fmt.Sprintf("%d-%d-%d", run.ID, run.RunNumber, run.RunAttempt)
 }
 
 func (g GitHubActions) GetJobID() string {
@@ -46,3 +88,84 @@ func (g GitHubActions) GetAttributes() map[string]string {
 		"github.sha":           os.Getenv("GITHUB_SHA"),
 	}
 }
+
+// GetStageID returns the pipeline ID as GitHub Actions doesn't expose a stage variable to its jobs, so the whole
+// pipeline is a single stage.
+func (g GitHubActions) GetStageID() string {
+	return g.GetPipelineID()
+}
+
+// GetStageName returns an empty string as GitHub Actions doesn't expose a stage variable to its jobs.
+func (g GitHubActions) GetStageName() string {
+	return ""
+}
+
+func (g GitHubActions) GetPipelineName() string {
+	return os.Getenv("GITHUB_WORKFLOW")
+}
+
+func (g GitHubActions) GetJobName() string {
+	return os.Getenv("GITHUB_JOB")
+}
+
+// GetRunID returns the ID GitHub gives the workflow run, which is shared by all of its attempts.
+func (g GitHubActions) GetRunID() string {
+	return os.Getenv("GITHUB_RUN_ID")
+}
+
+func (g GitHubActions) GetRunURL() string {
+	if g.GetRepositoryURL() == "" || os.Getenv("GITHUB_RUN_ID") == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/actions/runs/%s/attempts/%d", g.GetRepositoryURL(), os.Getenv("GITHUB_RUN_ID"), g.GetAttempt())
+}
+
+// GetJobRunID returns an empty string as GitHub Actions doesn't expose the ID of the job in the environment.
+func (g GitHubActions) GetJobRunID() string {
+	return ""
+}
+
+func (g GitHubActions) GetJobURL() string {
+	return ""
+}
+
+func (g GitHubActions) GetActor() string {
+	return os.Getenv("GITHUB_ACTOR")
+}
+
+func (g GitHubActions) GetTriggerEvent() string {
+	return os.Getenv("GITHUB_EVENT_NAME")
+}
+
+func (g GitHubActions) GetRepositoryName() string {
+	return repositoryName(os.Getenv("GITHUB_REPOSITORY"))
+}
+
+func (g GitHubActions) GetRepositoryURL() string {
+	if os.Getenv("GITHUB_SERVER_URL") == "" || os.Getenv("GITHUB_REPOSITORY") == "" {
+		return ""
+	}
+	return os.Getenv("GITHUB_SERVER_URL") + "/" + os.Getenv("GITHUB_REPOSITORY")
+}
+
+func (g GitHubActions) GetBranch() string {
+	// Pull request refs point to the merge commit, so the head branch is preferred
+	if head := os.Getenv("GITHUB_HEAD_REF"); head != "" {
+		return head
+	}
+	if os.Getenv("GITHUB_REF_TYPE") != "branch" {
+		return ""
+	}
+	return os.Getenv("GITHUB_REF_NAME")
+}
+
+func (g GitHubActions) GetTag() string {
+	if os.Getenv("GITHUB_REF_TYPE") != "tag" {
+		return ""
+	}
+	return os.Getenv("GITHUB_REF_NAME")
+}
+
+func (g GitHubActions) GetCommitSHA() string {
+	return os.Getenv("GITHUB_SHA")
+}
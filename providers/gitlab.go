@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"fmt"
 	"os"
 	"strings"
 )
@@ -42,3 +43,84 @@ func (g GitLabCI) GetAttributes() map[string]string {
 		"gitlab.job.sha":      os.Getenv("CI_COMMIT_SHA"),
 	}
 }
+
+func (g GitLabCI) GetBasePipelineID() string {
+	return g.GetPipelineID()
+}
+
+func (g GitLabCI) GetAttempt() int {
+	return 1
+}
+
+// GetUpstreamPipelineID returns an empty string as GitLab doesn't expose the ID of the pipeline triggering a
+// multi-project or child pipeline, whose trigger job can pass it as TRACI_UPSTREAM_PIPELINE_ID instead.
+func (g GitLabCI) GetUpstreamPipelineID() string {
+	return ""
+}
+
+// GetStageID returns the ID of the stage of the job, or the pipeline ID if the stage is unknown.
+func (g GitLabCI) GetStageID() string {
+	if g.GetStageName() == "" {
+		return g.GetPipelineID()
+	}
+	return fmt.Sprintf("%s-%s", g.GetPipelineID(), g.GetStageName())
+}
+
+func (g GitLabCI) GetStageName() string {
+	return os.Getenv("CI_JOB_STAGE")
+}
+
+func (g GitLabCI) GetPipelineName() string {
+	return os.Getenv("CI_PIPELINE_NAME")
+}
+
+func (g GitLabCI) GetJobName() string {
+	return os.Getenv("CI_JOB_NAME")
+}
+
+func (g GitLabCI) GetRunID() string {
+	return g.GetPipelineID()
+}
+
+func (g GitLabCI) GetRunURL() string {
+	return os.Getenv("CI_PIPELINE_URL")
+}
+
+func (g GitLabCI) GetJobRunID() string {
+	return g.GetJobID()
+}
+
+func (g GitLabCI) GetJobURL() string {
+	return os.Getenv("CI_JOB_URL")
+}
+
+func (g GitLabCI) GetActor() string {
+	return os.Getenv("GITLAB_USER_LOGIN")
+}
+
+func (g GitLabCI) GetTriggerEvent() string {
+	return os.Getenv("CI_PIPELINE_SOURCE")
+}
+
+func (g GitLabCI) GetRepositoryName() string {
+	return os.Getenv("CI_PROJECT_NAME")
+}
+
+func (g GitLabCI) GetRepositoryURL() string {
+	return os.Getenv("CI_PROJECT_URL")
+}
+
+func (g GitLabCI) GetBranch() string {
+	if g.GetTag() != "" {
+		return ""
+	}
+	return os.Getenv("CI_COMMIT_REF_NAME")
+}
+
+func (g GitLabCI) GetTag() string {
+	return os.Getenv("CI_COMMIT_TAG")
+}
+
+func (g GitLabCI) GetCommitSHA() string {
+	return os.Getenv("CI_COMMIT_SHA")
+}
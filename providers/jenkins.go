@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Jenkins maps a build to the pipeline and its stages to both stages and jobs, as the commands of a build are grouped
+// by the stage running them.
+type Jenkins struct{}
+
+func (j Jenkins) GetCIName() string {
+	return "Jenkins"
+}
+
+func (j Jenkins) GetPipelineID() string {
+	return os.Getenv("BUILD_TAG")
+}
+
+// GetJobID returns the ID of the stage running the command, or the pipeline ID outside of stages.
+func (j Jenkins) GetJobID() string {
+	return j.GetStageID()
+}
+
+func (j Jenkins) GetServiceName() string {
+	return strings.ToLower(j.GetCIName())
+}
+
+func (j Jenkins) GetSpanName() string {
+	if stage := j.GetStageName(); stage != "" {
+		return stage
+	}
+	return os.Getenv("JOB_BASE_NAME")
+}
+
+func (j Jenkins) GetAttributes() map[string]string {
+	// See https://www.jenkins.io/doc/book/pipeline/jenkinsfile/#using-environment-variables
+	return map[string]string{
+		"jenkins.build.number": os.Getenv("BUILD_NUMBER"),
+		"jenkins.build.tag":    os.Getenv("BUILD_TAG"),
+		"jenkins.build.url":    os.Getenv("BUILD_URL"),
+		"jenkins.job.name":     os.Getenv("JOB_NAME"),
+		"jenkins.stage.name":   os.Getenv("STAGE_NAME"),
+		"jenkins.node.name":    os.Getenv("NODE_NAME"),
+		"jenkins.branch":       os.Getenv("BRANCH_NAME"),
+		"jenkins.sha":          os.Getenv("GIT_COMMIT"),
+	}
+}
+
+func (j Jenkins) GetBasePipelineID() string {
+	return j.GetPipelineID()
+}
+
+// GetAttempt returns 1 as replaying or restarting a Jenkins build starts a new build with its own number.
+func (j Jenkins) GetAttempt() int {
+	return 1
+}
+
+// GetUpstreamPipelineID returns an empty string as Jenkins doesn't expose the cause of the build in the environment.
+func (j Jenkins) GetUpstreamPipelineID() string {
+	return ""
+}
+
+// GetStageID returns the ID of the stage, or the pipeline ID outside of stages.
+func (j Jenkins) GetStageID() string {
+	if j.GetStageName() == "" {
+		return j.GetPipelineID()
+	}
+	return fmt.Sprintf("%s-%s", j.GetPipelineID(), j.GetStageName())
+}
+
+func (j Jenkins) GetStageName() string {
+	return os.Getenv("STAGE_NAME")
+}
+
+func (j Jenkins) GetPipelineName() string {
+	return os.Getenv("JOB_NAME")
+}
+
+func (j Jenkins) GetJobName() string {
+	return j.GetStageName()
+}
+
+func (j Jenkins) GetRunID() string {
+	return j.GetPipelineID()
+}
+
+func (j Jenkins) GetRunURL() string {
+	return os.Getenv("BUILD_URL")
+}
+
+// GetJobRunID returns an empty string as Jenkins stages have no ID of their own.
+func (j Jenkins) GetJobRunID() string {
+	return ""
+}
+
+func (j Jenkins) GetJobURL() string {
+	return ""
+}
+
+func (j Jenkins) GetActor() string {
+	return ""
+}
+
+func (j Jenkins) GetTriggerEvent() string {
+	return ""
+}
+
+func (j Jenkins) GetRepositoryName() string {
+	return strings.TrimSuffix(repositoryName(os.Getenv("GIT_URL")), ".git")
+}
+
+func (j Jenkins) GetRepositoryURL() string {
+	return os.Getenv("GIT_URL")
+}
+
+func (j Jenkins) GetBranch() string {
+	if j.GetTag() != "" {
+		return ""
+	}
+	// Multibranch pipelines build pull requests as branches named after the change, such as PR-12
+	if branch := os.Getenv("CHANGE_BRANCH"); branch != "" {
+		return branch
+	}
+	if branch := os.Getenv("BRANCH_NAME"); branch != "" {
+		return branch
+	}
+	// The git plugin prefixes the branch with the remote, such as origin/main
+	branch := os.Getenv("GIT_BRANCH")
+	return branch[strings.Index(branch, "/")+1:]
+}
+
+func (j Jenkins) GetTag() string {
+	return os.Getenv("TAG_NAME")
+}
+
+func (j Jenkins) GetCommitSHA() string {
+	return os.Getenv("GIT_COMMIT")
+}
@@ -3,44 +3,180 @@ package providers
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"os"
+	"strings"
 )
 
+// detectors lists the CI providers in the order they are detected, with the environment variables marking their runs.
+var detectors = []struct {
+	provider Provider
+	markers  []string
+}{
+	{GitLabCI{}, []string{"GITLAB_CI"}},
+	{CircleCI{}, []string{"CIRCLECI"}},
+	{Travis{}, []string{"TRAVIS"}},
+	{GitHubActions{}, []string{"GITHUB_ACTION"}},
+	{Bitbucket{}, []string{"BITBUCKET_BUILD_NUMBER"}},
+	{AzurePipelines{}, []string{"TF_BUILD"}},
+	{Jenkins{}, []string{"JENKINS_URL"}},
+}
+
+// Detection is a CI provider whose marker environment variables are set.
+type Detection struct {
+	Name    string   `json:"name"`
+	EnvVars []string `json:"env_vars"`
+}
+
+// DetectProvider returns the first CI provider whose marker environment variables are set, or the DefaultProvider.
 func DetectProvider() Provider {
-	if _, present := os.LookupEnv("GITLAB_CI"); present {
-		return GitLabCI{}
-	} else if _, present := os.LookupEnv("CIRCLECI"); present {
-		return CircleCI{}
-	} else if _, present := os.LookupEnv("TRAVIS"); present {
-		return Travis{}
-	} else if _, present := os.LookupEnv("GITHUB_ACTION"); present {
-		return GitHubActions{}
-	} else if _, present := os.LookupEnv("BITBUCKET_BUILD_NUMBER"); present {
-		return Bitbucket{}
+	for _, d := range detectors {
+		if len(setEnvVars(d.markers)) > 0 {
+			return d.provider
+		}
 	}
-
 	return DefaultProvider{}
 }
 
+// Detect returns every CI provider whose marker environment variables are set, in the order they are detected.
+func Detect() []Detection {
+	var detections []Detection
+	for _, d := range detectors {
+		if envVars := setEnvVars(d.markers); len(envVars) > 0 {
+			detections = append(detections, Detection{Name: d.provider.GetCIName(), EnvVars: envVars})
+		}
+	}
+	return detections
+}
+
+// ProviderByName returns the provider named name, matching the name returned by GetCIName case-insensitively.
+func ProviderByName(name string) (Provider, error) {
+	for _, p := range append(providers(), DefaultProvider{}) {
+		if strings.EqualFold(p.GetCIName(), name) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown provider '%s', known providers are %v", name, ProviderNames())
+}
+
+// ProviderNames returns the names of the providers accepted by ProviderByName.
+func ProviderNames() []string {
+	var names []string
+	for _, p := range append(providers(), DefaultProvider{}) {
+		names = append(names, p.GetCIName())
+	}
+	return names
+}
+
+func providers() []Provider {
+	var providers []Provider
+	for _, d := range detectors {
+		providers = append(providers, d.provider)
+	}
+	return providers
+}
+
+func setEnvVars(names []string) []string {
+	var set []string
+	for _, name := range names {
+		if _, present := os.LookupEnv(name); present {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
 type Provider interface {
 	GetCIName() string
 	GetPipelineID() string
+	// GetBasePipelineID returns the ID shared by all attempts of the pipeline.
+	GetBasePipelineID() string
+	// GetAttempt returns the attempt number of the pipeline, starting at 1 and increased by each re-run.
+	GetAttempt() int
+	// GetUpstreamPipelineID returns the pipeline ID of the pipeline which triggered this one, or an empty string.
+	GetUpstreamPipelineID() string
+	// GetStageID returns the ID of the stage of the job, prefixed by the pipeline ID. Providers without stages return
+	// the pipeline ID, so the whole pipeline is a single stage.
+	GetStageID() string
+	// GetStageName returns the name of the stage of the job, or an empty string for providers without stages.
+	GetStageName() string
 	GetJobID() string
 	GetServiceName() string
 	GetSpanName() string
 	GetAttributes() map[string]string
+
+	// The following describe the run of the job. They return an empty string when the provider doesn't expose the value.
+
+	// GetPipelineName returns the display name of the pipeline.
+	GetPipelineName() string
+	// GetJobName returns the name of the job.
+	GetJobName() string
+	// GetRunID returns the ID the CI provider gives the pipeline run.
+	GetRunID() string
+	// GetRunURL returns the web URL of the pipeline run.
+	GetRunURL() string
+	// GetJobRunID returns the ID the CI provider gives the run of the job.
+	GetJobRunID() string
+	// GetJobURL returns the web URL of the job.
+	GetJobURL() string
+	// GetActor returns the user who triggered the pipeline.
+	GetActor() string
+	// GetTriggerEvent returns the event which triggered the pipeline, such as push, pull_request or schedule.
+	GetTriggerEvent() string
+	// GetRepositoryName returns the name of the repository, without its owner.
+	GetRepositoryName() string
+	// GetRepositoryURL returns the URL of the repository.
+	GetRepositoryURL() string
+	// GetBranch returns the branch being built, or an empty string when building a tag.
+	GetBranch() string
+	// GetTag returns the tag being built.
+	GetTag() string
+	// GetCommitSHA returns the SHA of the commit being built.
+	GetCommitSHA() string
 }
 
-type DefaultProvider struct{}
+// PipelineIDForAttempt returns the pipeline ID of an attempt of the pipeline of p. Providers supporting re-runs derive
+// the pipeline ID by appending the attempt number to the base pipeline ID.
+func PipelineIDForAttempt(p Provider, attempt int) string {
+	return fmt.Sprintf("%s-%d", p.GetBasePipelineID(), attempt)
+}
+
+// DefaultProvider is used outside of CI. Its IDs are random, unless SessionID groups local runs in the same pipeline.
+type DefaultProvider struct {
+	SessionID string
+}
 
 func (d DefaultProvider) GetCIName() string {
 	return "Default"
 }
 
 func (d DefaultProvider) GetPipelineID() string {
+	if d.SessionID != "" {
+		return d.SessionID
+	}
 	return d.genTraceID()
 }
 
+func (d DefaultProvider) GetBasePipelineID() string {
+	return d.GetPipelineID()
+}
+
+func (d DefaultProvider) GetAttempt() int {
+	return 1
+}
+
+func (d DefaultProvider) GetUpstreamPipelineID() string {
+	return ""
+}
+
+func (d DefaultProvider) GetStageID() string {
+	return d.GetPipelineID()
+}
+
+func (d DefaultProvider) GetStageName() string {
+	return ""
+}
+
 func (d DefaultProvider) GetJobID() string {
 	return d.genTraceID()
 }
@@ -64,3 +200,56 @@ func (d DefaultProvider) genTraceID() string {
 	}
 	return hex.EncodeToString(bytes)
 }
+
+func (d DefaultProvider) GetPipelineName() string {
+	return ""
+}
+
+func (d DefaultProvider) GetJobName() string {
+	return ""
+}
+
+// GetRunID returns the session ID, as the pipeline ID outside of a session is random on every call.
+func (d DefaultProvider) GetRunID() string {
+	return d.SessionID
+}
+
+func (d DefaultProvider) GetRunURL() string {
+	return ""
+}
+
+func (d DefaultProvider) GetJobRunID() string {
+	return ""
+}
+
+func (d DefaultProvider) GetJobURL() string {
+	return ""
+}
+
+func (d DefaultProvider) GetActor() string {
+	return ""
+}
+
+func (d DefaultProvider) GetTriggerEvent() string {
+	return ""
+}
+
+func (d DefaultProvider) GetRepositoryName() string {
+	return ""
+}
+
+func (d DefaultProvider) GetRepositoryURL() string {
+	return ""
+}
+
+func (d DefaultProvider) GetBranch() string {
+	return ""
+}
+
+func (d DefaultProvider) GetTag() string {
+	return ""
+}
+
+func (d DefaultProvider) GetCommitSHA() string {
+	return ""
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"github.com/stretchr/testify/assert"
 	"os"
 	"reflect"
 	"testing"
@@ -18,6 +19,8 @@ func TestDetectProvider(t *testing.T) {
 		{"travis", "TRAVIS", &Travis{}},
 		{"github", "GITHUB_ACTION", &GitHubActions{}},
 		{"bitbucket", "BITBUCKET_BUILD_NUMBER", &Bitbucket{}},
+		{"azure", "TF_BUILD", &AzurePipelines{}},
+		{"jenkins", "JENKINS_URL", &Jenkins{}},
 		{"default", "FOOBAR", &DefaultProvider{}},
 	}
 	for _, tc := range tests {
@@ -33,3 +36,398 @@ func TestDetectProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestDetect(t *testing.T) {
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("GITHUB_ACTION", "run")
+	os.Unsetenv("CIRCLECI")
+	os.Unsetenv("TRAVIS")
+	os.Unsetenv("BITBUCKET_BUILD_NUMBER")
+	os.Unsetenv("TF_BUILD")
+	os.Unsetenv("JENKINS_URL")
+
+	assert.Equal(t, []Detection{
+		{Name: "GitLab-CI", EnvVars: []string{"GITLAB_CI"}},
+		{Name: "GitHub-Actions", EnvVars: []string{"GITHUB_ACTION"}},
+	}, Detect())
+	assert.Equal(t, GitLabCI{}, DetectProvider())
+}
+
+func TestProviderByName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Provider
+		wantErr bool
+	}{
+		{"GitHub-Actions", GitHubActions{}, false},
+		{"gitlab-ci", GitLabCI{}, false},
+		{"default", DefaultProvider{}, false},
+		{"azure-pipelines", AzurePipelines{}, false},
+		{"Jenkins", Jenkins{}, false},
+		{"buildkite", nil, true},
+	}
+	for _, tc := range tests {
+		t.Run("Case for "+tc.name, func(t *testing.T) {
+			got, err := ProviderByName(tc.name)
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGitHubActionsAttempt(t *testing.T) {
+	tests := []struct {
+		name           string
+		attempt        string
+		wantAttempt    int
+		wantPipelineID string
+	}{
+		{"Case for first attempt", "1", 1, "42-7-1"},
+		{"Case for re-run", "3", 3, "42-7-3"},
+		{"Case for missing attempt", "", 1, "42-7-1"},
+		{"Case for invalid attempt", "foo", 1, "42-7-1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GITHUB_RUN_ID", "42")
+			t.Setenv("GITHUB_RUN_NUMBER", "7")
+			t.Setenv("GITHUB_RUN_ATTEMPT", tc.attempt)
+
+			g := GitHubActions{}
+			if got := g.GetBasePipelineID(); got != "42-7" {
+				t.Errorf("GetBasePipelineID() = %s, want 42-7", got)
+			}
+			if got := g.GetAttempt(); got != tc.wantAttempt {
+				t.Errorf("GetAttempt() = %d, want %d", got, tc.wantAttempt)
+			}
+			if got := g.GetPipelineID(); got != tc.wantPipelineID {
+				t.Errorf("GetPipelineID() = %s, want %s", got, tc.wantPipelineID)
+			}
+		})
+	}
+}
+
+func TestAzurePipelinesUpstreamPipelineID(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason string
+		want   string
+	}{
+		{"Case for pipeline resource trigger", "ResourceTrigger", "1234"},
+		{"Case for push", "IndividualCI", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("BUILD_REASON", tc.reason)
+			t.Setenv("RESOURCES_TRIGGERINGALIAS", "build")
+			t.Setenv("RESOURCES_PIPELINE_BUILD_RUNID", "1234")
+
+			assert.Equal(t, tc.want, AzurePipelines{}.GetUpstreamPipelineID())
+		})
+	}
+}
+
+func TestGetStageID(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  Provider
+		env       map[string]string
+		wantID    string
+		wantStage string
+	}{
+		{
+			name:      "Case for GitLab stage",
+			provider:  GitLabCI{},
+			env:       map[string]string{"CI_PIPELINE_ID": "100", "CI_JOB_STAGE": "test"},
+			wantID:    "100-test",
+			wantStage: "test",
+		},
+		{
+			name:      "Case for Travis build stage",
+			provider:  Travis{},
+			env:       map[string]string{"TRAVIS_BUILD_ID": "200", "TRAVIS_BUILD_STAGE_NAME": "Deploy"},
+			wantID:    "200-Deploy",
+			wantStage: "Deploy",
+		},
+		{
+			name:     "Case for Travis build without stages",
+			provider: Travis{},
+			env:      map[string]string{"TRAVIS_BUILD_ID": "200", "TRAVIS_BUILD_STAGE_NAME": ""},
+			wantID:   "200",
+		},
+		{
+			name:     "Case for GitLab job without stage",
+			provider: GitLabCI{},
+			env:      map[string]string{"CI_PIPELINE_ID": "100", "CI_JOB_STAGE": ""},
+			wantID:   "100",
+		},
+		{
+			name:      "Case for Azure Pipelines stage",
+			provider:  AzurePipelines{},
+			env:       map[string]string{"BUILD_BUILDID": "300", "SYSTEM_STAGEID": "6884a131-87da-5381-61f3-d7acc3b91d76", "SYSTEM_STAGENAME": "Build"},
+			wantID:    "300-6884a131-87da-5381-61f3-d7acc3b91d76",
+			wantStage: "Build",
+		},
+		{
+			name:     "Case for Azure Pipelines without stages",
+			provider: AzurePipelines{},
+			env:      map[string]string{"BUILD_BUILDID": "300", "SYSTEM_STAGEID": "", "SYSTEM_STAGENAME": ""},
+			wantID:   "300",
+		},
+		{
+			name:      "Case for Jenkins stage",
+			provider:  Jenkins{},
+			env:       map[string]string{"BUILD_TAG": "jenkins-traci-12", "STAGE_NAME": "Test"},
+			wantID:    "jenkins-traci-12-Test",
+			wantStage: "Test",
+		},
+		{
+			name:     "Case for Jenkins outside of stages",
+			provider: Jenkins{},
+			env:      map[string]string{"BUILD_TAG": "jenkins-traci-12", "STAGE_NAME": ""},
+			wantID:   "jenkins-traci-12",
+		},
+		{
+			name:     "Case for provider without stages",
+			provider: CircleCI{},
+			env:      map[string]string{"CIRCLE_WORKFLOW_ID": "abc"},
+			wantID:   "abc",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			if got := tc.provider.GetStageID(); got != tc.wantID {
+				t.Errorf("GetStageID() = %s, want %s", got, tc.wantID)
+			}
+			if got := tc.provider.GetStageName(); got != tc.wantStage {
+				t.Errorf("GetStageName() = %s, want %s", got, tc.wantStage)
+			}
+		})
+	}
+}
+
+func TestSemconvAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		env      map[string]string
+		want     map[string]string
+	}{
+		{
+			name:     "Case for GitHub Actions branch",
+			provider: GitHubActions{},
+			env: map[string]string{
+				"GITHUB_WORKFLOW":    "CI",
+				"GITHUB_RUN_ID":      "42",
+				"GITHUB_RUN_NUMBER":  "7",
+				"GITHUB_RUN_ATTEMPT": "1",
+				"GITHUB_JOB":         "build",
+				"GITHUB_SERVER_URL":  "https://github.com",
+				"GITHUB_REPOSITORY":  "nextrevision/traci",
+				"GITHUB_REF_NAME":    "main",
+				"GITHUB_HEAD_REF":    "",
+				"GITHUB_REF_TYPE":    "branch",
+				"GITHUB_SHA":         "abc123",
+			},
+			want: map[string]string{
+				CICDPipelineNameKey:       "CI",
+				CICDPipelineRunIDKey:      "42",
+				CICDPipelineRunURLFullKey: "https://github.com/nextrevision/traci/actions/runs/42/attempts/1",
+				CICDPipelineTaskNameKey:   "build",
+				VCSRepositoryNameKey:      "traci",
+				VCSRepositoryURLFullKey:   "https://github.com/nextrevision/traci",
+				VCSRefHeadNameKey:         "main",
+				VCSRefHeadTypeKey:         "branch",
+				VCSRefHeadRevisionKey:     "abc123",
+			},
+		},
+		{
+			name:     "Case for GitLab CI tag without pipeline name",
+			provider: GitLabCI{},
+			env: map[string]string{
+				"CI_PIPELINE_NAME":   "",
+				"CI_PIPELINE_ID":     "100",
+				"CI_PIPELINE_URL":    "https://gitlab.com/nextrevision/traci/-/pipelines/100",
+				"CI_JOB_NAME":        "release",
+				"CI_JOB_URL":         "https://gitlab.com/nextrevision/traci/-/jobs/7",
+				"CI_JOB_ID":          "7",
+				"CI_PROJECT_NAME":    "traci",
+				"CI_PROJECT_URL":     "https://gitlab.com/nextrevision/traci",
+				"CI_COMMIT_REF_NAME": "v1.0.0",
+				"CI_COMMIT_TAG":      "v1.0.0",
+				"CI_COMMIT_SHA":      "abc123",
+			},
+			want: map[string]string{
+				CICDPipelineRunIDKey:          "100",
+				CICDPipelineRunURLFullKey:     "https://gitlab.com/nextrevision/traci/-/pipelines/100",
+				CICDPipelineTaskNameKey:       "release",
+				CICDPipelineTaskRunIDKey:      "7",
+				CICDPipelineTaskRunURLFullKey: "https://gitlab.com/nextrevision/traci/-/jobs/7",
+				VCSRepositoryNameKey:          "traci",
+				VCSRepositoryURLFullKey:       "https://gitlab.com/nextrevision/traci",
+				VCSRefHeadNameKey:             "v1.0.0",
+				VCSRefHeadTypeKey:             "tag",
+				VCSRefHeadRevisionKey:         "abc123",
+			},
+		},
+		{
+			name:     "Case for Azure Pipelines job attempt",
+			provider: AzurePipelines{},
+			env: map[string]string{
+				"BUILD_DEFINITIONNAME":  "CI",
+				"BUILD_BUILDID":         "300",
+				"SYSTEM_COLLECTIONURI":  "",
+				"SYSTEM_JOBID":          "12f1170f-54f2-53f3-20dd-22fc7dff55f9",
+				"SYSTEM_JOBATTEMPT":     "2",
+				"SYSTEM_JOBDISPLAYNAME": "build",
+				"BUILD_REPOSITORY_NAME": "nextrevision/traci",
+				"BUILD_REPOSITORY_URI":  "https://github.com/nextrevision/traci",
+				"BUILD_SOURCEBRANCH":    "refs/heads/main",
+				"BUILD_SOURCEVERSION":   "abc123",
+			},
+			want: map[string]string{
+				CICDPipelineNameKey:      "CI",
+				CICDPipelineRunIDKey:     "300",
+				CICDPipelineTaskNameKey:  "build",
+				CICDPipelineTaskRunIDKey: "12f1170f-54f2-53f3-20dd-22fc7dff55f9",
+				VCSRepositoryNameKey:     "traci",
+				VCSRepositoryURLFullKey:  "https://github.com/nextrevision/traci",
+				VCSRefHeadNameKey:        "main",
+				VCSRefHeadTypeKey:        "branch",
+				VCSRefHeadRevisionKey:    "abc123",
+			},
+		},
+		{
+			name:     "Case for Jenkins stage",
+			provider: Jenkins{},
+			env: map[string]string{
+				"JOB_NAME":      "traci",
+				"BUILD_TAG":     "jenkins-traci-5",
+				"BUILD_URL":     "https://jenkins.example.com/job/traci/5/",
+				"STAGE_NAME":    "build",
+				"GIT_URL":       "https://github.com/nextrevision/traci.git",
+				"TAG_NAME":      "",
+				"CHANGE_BRANCH": "",
+				"BRANCH_NAME":   "main",
+				"GIT_COMMIT":    "abc123",
+			},
+			want: map[string]string{
+				CICDPipelineNameKey:       "traci",
+				CICDPipelineRunIDKey:      "jenkins-traci-5",
+				CICDPipelineRunURLFullKey: "https://jenkins.example.com/job/traci/5/",
+				CICDPipelineTaskNameKey:   "build",
+				VCSRepositoryNameKey:      "traci",
+				VCSRepositoryURLFullKey:   "https://github.com/nextrevision/traci.git",
+				VCSRefHeadNameKey:         "main",
+				VCSRefHeadTypeKey:         "branch",
+				VCSRefHeadRevisionKey:     "abc123",
+			},
+		},
+		{
+			name:     "Case for default provider",
+			provider: DefaultProvider{},
+			want:     map[string]string{},
+		},
+		{
+			name:     "Case for default provider in a session",
+			provider: DefaultProvider{SessionID: "0123456789abcdef"},
+			want: map[string]string{
+				CICDPipelineRunIDKey: "0123456789abcdef",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			if got := SemconvAttributes(tc.provider); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("SemconvAttributes() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetBranchAndTag(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   Provider
+		env        map[string]string
+		wantBranch string
+		wantTag    string
+	}{
+		{
+			name:       "Case for GitHub Actions push",
+			provider:   GitHubActions{},
+			env:        map[string]string{"GITHUB_REF_NAME": "main", "GITHUB_REF_TYPE": "branch", "GITHUB_HEAD_REF": ""},
+			wantBranch: "main",
+		},
+		{
+			name:       "Case for GitHub Actions pull request",
+			provider:   GitHubActions{},
+			env:        map[string]string{"GITHUB_REF_NAME": "12/merge", "GITHUB_REF_TYPE": "branch", "GITHUB_HEAD_REF": "feature"},
+			wantBranch: "feature",
+		},
+		{
+			name:     "Case for GitHub Actions tag",
+			provider: GitHubActions{},
+			env:      map[string]string{"GITHUB_REF_NAME": "v1.0.0", "GITHUB_REF_TYPE": "tag", "GITHUB_HEAD_REF": ""},
+			wantTag:  "v1.0.0",
+		},
+		{
+			name:     "Case for GitLab CI tag",
+			provider: GitLabCI{},
+			env:      map[string]string{"CI_COMMIT_REF_NAME": "v1.0.0", "CI_COMMIT_TAG": "v1.0.0"},
+			wantTag:  "v1.0.0",
+		},
+		{
+			name:       "Case for Azure Pipelines pull request",
+			provider:   AzurePipelines{},
+			env:        map[string]string{"BUILD_SOURCEBRANCH": "refs/pull/12/merge", "SYSTEM_PULLREQUEST_SOURCEBRANCH": "refs/heads/feature"},
+			wantBranch: "feature",
+		},
+		{
+			name:     "Case for Azure Pipelines tag",
+			provider: AzurePipelines{},
+			env:      map[string]string{"BUILD_SOURCEBRANCH": "refs/tags/v1.0.0", "SYSTEM_PULLREQUEST_SOURCEBRANCH": ""},
+			wantTag:  "v1.0.0",
+		},
+		{
+			name:       "Case for Jenkins git plugin branch",
+			provider:   Jenkins{},
+			env:        map[string]string{"TAG_NAME": "", "CHANGE_BRANCH": "", "BRANCH_NAME": "", "GIT_BRANCH": "origin/feature/x"},
+			wantBranch: "feature/x",
+		},
+		{
+			name:       "Case for Jenkins multibranch pull request",
+			provider:   Jenkins{},
+			env:        map[string]string{"TAG_NAME": "", "CHANGE_BRANCH": "feature", "BRANCH_NAME": "PR-12"},
+			wantBranch: "feature",
+		},
+		{
+			name:       "Case for Travis CI pull request",
+			provider:   Travis{},
+			env:        map[string]string{"TRAVIS_BRANCH": "main", "TRAVIS_PULL_REQUEST_BRANCH": "feature", "TRAVIS_TAG": ""},
+			wantBranch: "feature",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			if got := tc.provider.GetBranch(); got != tc.wantBranch {
+				t.Errorf("GetBranch() = %s, want %s", got, tc.wantBranch)
+			}
+			if got := tc.provider.GetTag(); got != tc.wantTag {
+				t.Errorf("GetTag() = %s, want %s", got, tc.wantTag)
+			}
+		})
+	}
+}
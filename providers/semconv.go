@@ -0,0 +1,62 @@
+package providers
+
+import "strings"
+
+// Keys of the OpenTelemetry CI/CD and VCS semantic convention attributes, see
+// https://opentelemetry.io/docs/specs/semconv/attributes-registry/cicd/ and
+// https://opentelemetry.io/docs/specs/semconv/attributes-registry/vcs/
+const (
+	CICDPipelineNameKey           = "cicd.pipeline.name"
+	CICDPipelineRunIDKey          = "cicd.pipeline.run.id"
+	CICDPipelineRunURLFullKey     = "cicd.pipeline.run.url.full"
+	CICDPipelineTaskNameKey       = "cicd.pipeline.task.name"
+	CICDPipelineTaskRunIDKey      = "cicd.pipeline.task.run.id"
+	CICDPipelineTaskRunURLFullKey = "cicd.pipeline.task.run.url.full"
+	VCSRepositoryNameKey          = "vcs.repository.name"
+	VCSRepositoryURLFullKey       = "vcs.repository.url.full"
+	VCSRefHeadNameKey             = "vcs.ref.head.name"
+	VCSRefHeadRevisionKey         = "vcs.ref.head.revision"
+	VCSRefHeadTypeKey             = "vcs.ref.head.type"
+)
+
+// Values of the vcs.ref.head.type attribute.
+const (
+	vcsRefTypeBranch = "branch"
+	vcsRefTypeTag    = "tag"
+)
+
+// SemconvAttributes returns the OpenTelemetry CI/CD and VCS semantic convention attributes describing the job of p,
+// leaving out the values p doesn't expose.
+func SemconvAttributes(p Provider) map[string]string {
+	attributes := map[string]string{
+		CICDPipelineNameKey:           p.GetPipelineName(),
+		CICDPipelineRunIDKey:          p.GetRunID(),
+		CICDPipelineRunURLFullKey:     p.GetRunURL(),
+		CICDPipelineTaskNameKey:       p.GetJobName(),
+		CICDPipelineTaskRunIDKey:      p.GetJobRunID(),
+		CICDPipelineTaskRunURLFullKey: p.GetJobURL(),
+		VCSRepositoryNameKey:          p.GetRepositoryName(),
+		VCSRepositoryURLFullKey:       p.GetRepositoryURL(),
+		VCSRefHeadRevisionKey:         p.GetCommitSHA(),
+	}
+	switch {
+	case p.GetTag() != "":
+		attributes[VCSRefHeadNameKey] = p.GetTag()
+		attributes[VCSRefHeadTypeKey] = vcsRefTypeTag
+	case p.GetBranch() != "":
+		attributes[VCSRefHeadNameKey] = p.GetBranch()
+		attributes[VCSRefHeadTypeKey] = vcsRefTypeBranch
+	}
+
+	for k, v := range attributes {
+		if v == "" {
+			delete(attributes, k)
+		}
+	}
+	return attributes
+}
+
+// repositoryName returns the name of the repository at the end of slug, such as `owner/name`.
+func repositoryName(slug string) string {
+	return slug[strings.LastIndex(slug, "/")+1:]
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"fmt"
 	"os"
 	"strings"
 )
@@ -41,3 +42,87 @@ func (t Travis) GetAttributes() map[string]string {
 	}
 
 }
+
+func (t Travis) GetBasePipelineID() string {
+	return t.GetPipelineID()
+}
+
+func (t Travis) GetAttempt() int {
+	return 1
+}
+
+// GetUpstreamPipelineID returns an empty string as Travis CI doesn't expose the pipeline triggering this one.
+func (t Travis) GetUpstreamPipelineID() string {
+	return ""
+}
+
+// GetStageID returns the ID of the build stage, or the pipeline ID for builds without stages.
+func (t Travis) GetStageID() string {
+	if t.GetStageName() == "" {
+		return t.GetPipelineID()
+	}
+	return fmt.Sprintf("%s-%s", t.GetPipelineID(), t.GetStageName())
+}
+
+func (t Travis) GetStageName() string {
+	return os.Getenv("TRAVIS_BUILD_STAGE_NAME")
+}
+
+func (t Travis) GetPipelineName() string {
+	return ""
+}
+
+func (t Travis) GetJobName() string {
+	return os.Getenv("TRAVIS_JOB_NAME")
+}
+
+func (t Travis) GetRunID() string {
+	return t.GetPipelineID()
+}
+
+func (t Travis) GetRunURL() string {
+	return os.Getenv("TRAVIS_BUILD_WEB_URL")
+}
+
+func (t Travis) GetJobRunID() string {
+	return t.GetJobID()
+}
+
+func (t Travis) GetJobURL() string {
+	return os.Getenv("TRAVIS_JOB_WEB_URL")
+}
+
+func (t Travis) GetActor() string {
+	return ""
+}
+
+func (t Travis) GetTriggerEvent() string {
+	return os.Getenv("TRAVIS_EVENT_TYPE")
+}
+
+func (t Travis) GetRepositoryName() string {
+	return repositoryName(os.Getenv("TRAVIS_REPO_SLUG"))
+}
+
+func (t Travis) GetRepositoryURL() string {
+	return ""
+}
+
+func (t Travis) GetBranch() string {
+	if t.GetTag() != "" {
+		return ""
+	}
+	// TRAVIS_BRANCH is the target branch of pull request builds
+	if branch := os.Getenv("TRAVIS_PULL_REQUEST_BRANCH"); branch != "" {
+		return branch
+	}
+	return os.Getenv("TRAVIS_BRANCH")
+}
+
+func (t Travis) GetTag() string {
+	return os.Getenv("TRAVIS_TAG")
+}
+
+func (t Travis) GetCommitSHA() string {
+	return os.Getenv("TRAVIS_COMMIT")
+}
@@ -0,0 +1,120 @@
+// Package session groups local runs of traci under the same pipeline ID, so consecutive commands of a script or
+// Makefile share a trace like they would in CI.
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultIdleTimeout is the time after the last use of a session after which it expires.
+const DefaultIdleTimeout = 30 * time.Minute
+
+// ErrNoSession is returned when no session was started or the session expired.
+var ErrNoSession = errors.New("no active session")
+
+// Session is a local pipeline, started by `traci session start`.
+type Session struct {
+	ID          string        `json:"id"`
+	Started     time.Time     `json:"started"`
+	LastUsed    time.Time     `json:"last_used"`
+	IdleTimeout time.Duration `json:"idle_timeout_ns"`
+}
+
+// Expired reports whether the session has not been used for longer than its idle timeout. Sessions without an idle
+// timeout never expire.
+func (s *Session) Expired(now time.Time) bool {
+	return s.IdleTimeout > 0 && now.Sub(s.LastUsed) > s.IdleTimeout
+}
+
+// DefaultPath returns the session file in the user's cache directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "traci", "session.json"), nil
+}
+
+// Start creates a session with a random ID, replacing any session stored at path.
+func Start(path string, idleTimeout time.Duration, now time.Time) (*Session, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	s := &Session{
+		ID:          hex.EncodeToString(id),
+		Started:     now,
+		LastUsed:    now,
+		IdleTimeout: idleTimeout,
+	}
+	return s, write(path, s)
+}
+
+// Read returns the session stored at path, or ErrNoSession if there is none or it expired.
+func Read(path string, now time.Time) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoSession
+	} else if err != nil {
+		return nil, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if s.ID == "" || s.Expired(now) {
+		return nil, ErrNoSession
+	}
+	return &s, nil
+}
+
+// Resume returns the session stored at path like Read, extending it by recording now as its last use.
+func Resume(path string, now time.Time) (*Session, error) {
+	s, err := Read(path, now)
+	if err != nil {
+		return nil, err
+	}
+	s.LastUsed = now
+	return s, write(path, s)
+}
+
+// End removes the session stored at path. Ending when there is no session is not an error.
+func End(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// write replaces the session file through a rename, so concurrent runs never read a partially written file.
+func write(path string, s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
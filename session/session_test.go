@@ -0,0 +1,63 @@
+package session
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traci", "session.json")
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := Read(path, start)
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	started, err := Start(path, time.Minute, start)
+	assert.Nil(t, err)
+	assert.Len(t, started.ID, 32)
+
+	// Every run extends the session by its idle timeout
+	resumed, err := Resume(path, start.Add(50*time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, started.ID, resumed.ID)
+	resumed, err = Resume(path, start.Add(100*time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, started.ID, resumed.ID)
+
+	_, err = Resume(path, start.Add(200*time.Second))
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	restarted, err := Start(path, 0, start)
+	assert.Nil(t, err)
+	assert.NotEqual(t, started.ID, restarted.ID)
+	_, err = Read(path, start.Add(24*time.Hour))
+	assert.Nil(t, err)
+
+	assert.Nil(t, End(path))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, End(path))
+}
+
+func TestExpired(t *testing.T) {
+	lastUsed := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		idleTimeout time.Duration
+		now         time.Time
+		want        bool
+	}{
+		{"Case for recent use", time.Minute, lastUsed.Add(time.Second), false},
+		{"Case for idle session", time.Minute, lastUsed.Add(2 * time.Minute), true},
+		{"Case for no idle timeout", 0, lastUsed.Add(24 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Session{ID: "id", LastUsed: lastUsed, IdleTimeout: tt.idleTimeout}
+			assert.Equal(t, tt.want, s.Expired(tt.now))
+		})
+	}
+}
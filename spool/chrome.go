@@ -0,0 +1,188 @@
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ChromeEvent is an event of the Chrome trace-event format, which Perfetto and chrome://tracing can load.
+// See https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type ChromeEvent struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat,omitempty"`
+	Ph   string            `json:"ph"`
+	Ts   float64           `json:"ts"`
+	Dur  float64           `json:"dur,omitempty"`
+	Pid  int               `json:"pid"`
+	Tid  int               `json:"tid"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+type chromeTrace struct {
+	TraceEvents     []ChromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+// ChromeEvents converts spans into complete (X) events. Each traci process becomes a Chrome process, identified by the
+// process.pid resource attribute, and overlapping spans which do not nest are placed on separate threads so viewers
+// render them side by side.
+func ChromeEvents(records []Record) []ChromeEvent {
+	sorted := append([]Record{}, records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].StartTimeUnixNano == sorted[j].StartTimeUnixNano {
+			return sorted[i].EndTimeUnixNano > sorted[j].EndTimeUnixNano
+		}
+		return sorted[i].StartTimeUnixNano < sorted[j].StartTimeUnixNano
+	})
+
+	var events []ChromeEvent
+	processNames := map[int]string{}
+	// lanes holds the end times of the open spans of each thread of a process, innermost last
+	lanes := map[int][][]Uint64{}
+
+	for _, r := range sorted {
+		pid := chromePID(r)
+		if _, ok := processNames[pid]; !ok {
+			service, _ := r.Attribute("service.name")
+			processNames[pid] = fmt.Sprintf("%s (%d)", service, pid)
+		}
+
+		tid := -1
+		for i, lane := range lanes[pid] {
+			for len(lane) > 0 && lane[len(lane)-1] <= r.StartTimeUnixNano {
+				lane = lane[:len(lane)-1]
+			}
+			lanes[pid][i] = lane
+			if tid < 0 && (len(lane) == 0 || r.EndTimeUnixNano <= lane[len(lane)-1]) {
+				tid = i
+			}
+		}
+		if tid < 0 {
+			tid = len(lanes[pid])
+			lanes[pid] = append(lanes[pid], nil)
+		}
+		lanes[pid][tid] = append(lanes[pid][tid], r.EndTimeUnixNano)
+
+		events = append(events, chromeEvent(r, pid, tid+1))
+	}
+
+	pids := make([]int, 0, len(processNames))
+	for pid := range processNames {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	for _, pid := range pids {
+		events = append(events, ChromeEvent{
+			Name: "process_name",
+			Ph:   "M",
+			Pid:  pid,
+			Args: map[string]string{"name": processNames[pid]},
+		})
+	}
+
+	return events
+}
+
+// WriteChromeTrace writes the spans as a Chrome trace-event file in the JSON object format.
+func WriteChromeTrace(w io.Writer, records []Record) error {
+	encoder := json.NewEncoder(w)
+	return encoder.Encode(chromeTrace{TraceEvents: ChromeEvents(records), DisplayTimeUnit: "ms"})
+}
+
+func chromeEvent(r Record, pid int, tid int) ChromeEvent {
+	args := map[string]string{
+		"trace_id": r.TraceID,
+		"span_id":  r.SpanID,
+	}
+	if r.ParentSpanID != "" {
+		args["parent_span_id"] = r.ParentSpanID
+	}
+	if r.Status.Code == StatusCodeError {
+		args["status"] = "error"
+		if r.Status.Message != "" {
+			args["status_message"] = r.Status.Message
+		}
+	}
+	for _, kv := range r.Attributes {
+		args[kv.Key] = kv.Value.String()
+	}
+
+	service, _ := r.Attribute("service.name")
+	return ChromeEvent{
+		Name: r.Name,
+		Cat:  service,
+		Ph:   "X",
+		Ts:   float64(r.StartTimeUnixNano) / 1e3,
+		Dur:  float64(r.EndTimeUnixNano-r.StartTimeUnixNano) / 1e3,
+		Pid:  pid,
+		Tid:  tid,
+		Args: args,
+	}
+}
+
+// chromePID returns the process.pid resource attribute, or a stable identifier derived from the service name.
+func chromePID(r Record) int {
+	if value, ok := r.Attribute("process.pid"); ok {
+		if pid, err := strconv.Atoi(value); err == nil {
+			return pid
+		}
+	}
+	service, _ := r.Attribute("service.name")
+	h := fnv.New32a()
+	h.Write([]byte(service))
+	return int(h.Sum32() & 0x7fffffff)
+}
+
+// ChromeExporter is a SpanExporter appending spans to a Chrome trace-event file in the JSON array format. The closing
+// bracket of the array is optional in this format, which lets several traci invocations append to the same file.
+type ChromeExporter struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewChromeExporter creates a ChromeExporter appending to the file at path.
+func NewChromeExporter(path string) *ChromeExporter {
+	return &ChromeExporter{path: path}
+}
+
+func (e *ChromeExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	f, err := os.OpenFile(e.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var buf []byte
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		buf = append(buf, "[\n"...)
+	}
+	for _, event := range ChromeEvents(FromReadOnlySpans(spans).Records()) {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), ",\n"...)
+	}
+
+	_, err = f.Write(buf)
+	return err
+}
+
+func (e *ChromeExporter) Shutdown(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package spool
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChromeEvents(t *testing.T) {
+	service := "traci"
+	pid := Int64(42)
+	resource := []KeyValue{
+		{Key: "service.name", Value: AnyValue{StringValue: &service}},
+		{Key: "process.pid", Value: AnyValue{IntValue: &pid}},
+	}
+	records := []Record{
+		{Span: Span{Name: "job", SpanID: "a", StartTimeUnixNano: 0, EndTimeUnixNano: 10000}, Resource: resource},
+		{Span: Span{Name: "first", SpanID: "b", ParentSpanID: "a", StartTimeUnixNano: 1000, EndTimeUnixNano: 6000}, Resource: resource},
+		{Span: Span{Name: "parallel", SpanID: "c", ParentSpanID: "a", StartTimeUnixNano: 2000, EndTimeUnixNano: 8000}, Resource: resource},
+		{Span: Span{Name: "second", SpanID: "d", ParentSpanID: "a", StartTimeUnixNano: 7000, EndTimeUnixNano: 9000, Status: Status{Code: StatusCodeError}}, Resource: resource},
+	}
+
+	events := ChromeEvents(records)
+	assert.Len(t, events, 5)
+
+	tids := map[string]int{}
+	for _, event := range events {
+		if event.Ph == "X" {
+			assert.Equal(t, 42, event.Pid)
+			tids[event.Name] = event.Tid
+		}
+	}
+	// Nested spans share a thread while the overlapping sibling is moved to another one
+	assert.Equal(t, map[string]int{"job": 1, "first": 1, "parallel": 2, "second": 1}, tids)
+
+	assert.Equal(t, "process_name", events[4].Name)
+	assert.Equal(t, "traci (42)", events[4].Args["name"])
+	assert.Equal(t, 1.0, events[1].Ts)
+	assert.Equal(t, "error", events[3].Args["status"])
+}
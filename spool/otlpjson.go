@@ -0,0 +1,321 @@
+package spool
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TracesData mirrors the subset of the OTLP JSON encoding of a TracesData message used by traci, which is also the
+// format written by the OpenTelemetry Collector's file exporter.
+// See https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type TracesData struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+type Resource struct {
+	Attributes []KeyValue `json:"attributes,omitempty"`
+}
+
+type ScopeSpans struct {
+	Scope Scope  `json:"scope"`
+	Spans []Span `json:"spans"`
+}
+
+type Scope struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+type Span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	TraceState        string     `json:"traceState,omitempty"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind,omitempty"`
+	StartTimeUnixNano Uint64     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   Uint64     `json:"endTimeUnixNano"`
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	Events            []Event    `json:"events,omitempty"`
+	Links             []Link     `json:"links,omitempty"`
+	Status            Status     `json:"status"`
+}
+
+type Event struct {
+	TimeUnixNano Uint64     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []KeyValue `json:"attributes,omitempty"`
+}
+
+type Link struct {
+	TraceID    string     `json:"traceId"`
+	SpanID     string     `json:"spanId"`
+	Attributes []KeyValue `json:"attributes,omitempty"`
+}
+
+// Status codes follow the OTLP enum, where 1 is ok and 2 is error.
+type Status struct {
+	Message string `json:"message,omitempty"`
+	Code    int    `json:"code,omitempty"`
+}
+
+const (
+	StatusCodeUnset = 0
+	StatusCodeOk    = 1
+	StatusCodeError = 2
+)
+
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+type AnyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *Int64      `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *ArrayValue `json:"arrayValue,omitempty"`
+}
+
+type ArrayValue struct {
+	Values []AnyValue `json:"values"`
+}
+
+// String returns the value formatted as a string, with arrays formatted as a comma separated list.
+func (v AnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.ArrayValue != nil:
+		values := make([]string, len(v.ArrayValue.Values))
+		for i, value := range v.ArrayValue.Values {
+			values[i] = value.String()
+		}
+		return strings.Join(values, ",")
+	}
+	return ""
+}
+
+// Int64 is a signed 64-bit integer, which OTLP JSON encodes as a decimal string.
+type Int64 int64
+
+func (i Int64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(i), 10))
+}
+
+func (i *Int64) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64 value %s: %w", data, err)
+	}
+	*i = Int64(value)
+	return nil
+}
+
+// Uint64 is an unsigned 64-bit integer, which OTLP JSON encodes as a decimal string.
+type Uint64 uint64
+
+func (u Uint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(u), 10))
+}
+
+func (u *Uint64) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid uint64 value %s: %w", data, err)
+	}
+	*u = Uint64(value)
+	return nil
+}
+
+// FromReadOnlySpans converts spans recorded by the SDK into TracesData, grouping them by resource and scope.
+func FromReadOnlySpans(spans []sdktrace.ReadOnlySpan) TracesData {
+	var data TracesData
+	resources := map[string]int{}
+	scopes := map[string]int{}
+
+	for _, s := range spans {
+		resourceKey := s.Resource().Encoded(attribute.DefaultEncoder())
+		ri, ok := resources[resourceKey]
+		if !ok {
+			ri = len(data.ResourceSpans)
+			resources[resourceKey] = ri
+			data.ResourceSpans = append(data.ResourceSpans, ResourceSpans{
+				Resource: Resource{Attributes: fromAttributes(s.Resource().Attributes())},
+			})
+		}
+
+		scopeKey := resourceKey + "\x00" + s.InstrumentationScope().Name + "\x00" + s.InstrumentationScope().Version
+		si, ok := scopes[scopeKey]
+		if !ok {
+			si = len(data.ResourceSpans[ri].ScopeSpans)
+			scopes[scopeKey] = si
+			data.ResourceSpans[ri].ScopeSpans = append(data.ResourceSpans[ri].ScopeSpans, ScopeSpans{
+				Scope: Scope{Name: s.InstrumentationScope().Name, Version: s.InstrumentationScope().Version},
+			})
+		}
+
+		scopeSpans := &data.ResourceSpans[ri].ScopeSpans[si]
+		scopeSpans.Spans = append(scopeSpans.Spans, fromReadOnlySpan(s))
+	}
+
+	return data
+}
+
+func fromReadOnlySpan(s sdktrace.ReadOnlySpan) Span {
+	span := Span{
+		TraceID:           s.SpanContext().TraceID().String(),
+		SpanID:            s.SpanContext().SpanID().String(),
+		TraceState:        s.SpanContext().TraceState().String(),
+		Name:              s.Name(),
+		Kind:              int(s.SpanKind()),
+		StartTimeUnixNano: Uint64(s.StartTime().UnixNano()),
+		EndTimeUnixNano:   Uint64(s.EndTime().UnixNano()),
+		Attributes:        fromAttributes(s.Attributes()),
+		Status:            Status{Message: s.Status().Description},
+	}
+	if s.Parent().HasSpanID() {
+		span.ParentSpanID = s.Parent().SpanID().String()
+	}
+
+	switch s.Status().Code {
+	case codes.Ok:
+		span.Status.Code = StatusCodeOk
+	case codes.Error:
+		span.Status.Code = StatusCodeError
+	}
+
+	for _, e := range s.Events() {
+		span.Events = append(span.Events, Event{
+			TimeUnixNano: Uint64(e.Time.UnixNano()),
+			Name:         e.Name,
+			Attributes:   fromAttributes(e.Attributes),
+		})
+	}
+	for _, l := range s.Links() {
+		span.Links = append(span.Links, Link{
+			TraceID:    l.SpanContext.TraceID().String(),
+			SpanID:     l.SpanContext.SpanID().String(),
+			Attributes: fromAttributes(l.Attributes),
+		})
+	}
+
+	return span
+}
+
+func fromAttributes(attrs []attribute.KeyValue) []KeyValue {
+	var kvs []KeyValue
+	for _, attr := range attrs {
+		kvs = append(kvs, KeyValue{Key: string(attr.Key), Value: fromValue(attr.Value)})
+	}
+	return kvs
+}
+
+func fromValue(v attribute.Value) AnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return AnyValue{BoolValue: &b}
+	case attribute.INT64:
+		i := Int64(v.AsInt64())
+		return AnyValue{IntValue: &i}
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return AnyValue{DoubleValue: &f}
+	case attribute.BOOLSLICE:
+		var values []AnyValue
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, fromValue(attribute.BoolValue(b)))
+		}
+		return AnyValue{ArrayValue: &ArrayValue{Values: values}}
+	case attribute.INT64SLICE:
+		var values []AnyValue
+		for _, i := range v.AsInt64Slice() {
+			values = append(values, fromValue(attribute.Int64Value(i)))
+		}
+		return AnyValue{ArrayValue: &ArrayValue{Values: values}}
+	case attribute.FLOAT64SLICE:
+		var values []AnyValue
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, fromValue(attribute.Float64Value(f)))
+		}
+		return AnyValue{ArrayValue: &ArrayValue{Values: values}}
+	case attribute.STRINGSLICE:
+		var values []AnyValue
+		for _, s := range v.AsStringSlice() {
+			values = append(values, fromValue(attribute.StringValue(s)))
+		}
+		return AnyValue{ArrayValue: &ArrayValue{Values: values}}
+	default:
+		s := v.Emit()
+		return AnyValue{StringValue: &s}
+	}
+}
+
+// Record is a span along with the attributes of its resource, flattened from TracesData for analysis.
+type Record struct {
+	Span
+	Resource []KeyValue
+}
+
+// Records flattens the spans of all resources and scopes.
+func (d TracesData) Records() []Record {
+	var records []Record
+	for _, rs := range d.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				records = append(records, Record{Span: span, Resource: rs.Resource.Attributes})
+			}
+		}
+	}
+	return records
+}
+
+func (r Record) StartTime() time.Time {
+	return time.Unix(0, int64(r.StartTimeUnixNano))
+}
+
+func (r Record) EndTime() time.Time {
+	return time.Unix(0, int64(r.EndTimeUnixNano))
+}
+
+func (r Record) Duration() time.Duration {
+	return r.EndTime().Sub(r.StartTime())
+}
+
+// Attribute returns the value of the span attribute key formatted as a string, falling back to the resource attribute
+// with the same key.
+func (r Record) Attribute(key string) (string, bool) {
+	value, ok := r.Value(key)
+	return value.String(), ok
+}
+
+// Value returns the value of the span attribute key, falling back to the resource attribute with the same key.
+func (r Record) Value(key string) (AnyValue, bool) {
+	for _, attrs := range [][]KeyValue{r.Attributes, r.Resource} {
+		for _, kv := range attrs {
+			if kv.Key == key {
+				return kv.Value, true
+			}
+		}
+	}
+	return AnyValue{}, false
+}
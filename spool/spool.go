@@ -0,0 +1,113 @@
+// Package spool stores spans locally as OTLP JSON lines so the spans of several traci invocations can be merged,
+// summarized and analyzed without a tracing backend.
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Exporter is a SpanExporter appending spans to a file in the spool directory. Each process writes to its own file
+// so concurrent invocations never interleave their writes.
+type Exporter struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewExporter creates an Exporter writing to the spool directory dir, creating the directory if needed.
+func NewExporter(dir string) (*Exporter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Exporter{path: filepath.Join(dir, fmt.Sprintf("spans-%d.jsonl", os.Getpid()))}, nil
+}
+
+// ExportSpans appends the spans to the spool file as a single line of OTLP JSON.
+func (e *Exporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	line, err := json.Marshal(FromReadOnlySpans(spans))
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	f, err := os.OpenFile(e.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (e *Exporter) Shutdown(_ context.Context) error {
+	return nil
+}
+
+// Read returns the spans of all given paths. Paths may be spool directories, whose .jsonl files written by the
+// Exporter are read, or individual files containing OTLP JSON, either as one document or as one document per line.
+// Other files in spool directories, such as a Chrome trace-event file written next to them, are ignored.
+func Read(paths ...string) ([]Record, error) {
+	var records []Record
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files := []string{path}
+		if info.IsDir() {
+			files, _ = filepath.Glob(filepath.Join(path, "*.jsonl"))
+			sort.Strings(files)
+		}
+
+		for _, file := range files {
+			fileRecords, err := readFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			records = append(records, fileRecords...)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartTimeUnixNano < records[j].StartTimeUnixNano
+	})
+	return records, nil
+}
+
+func readFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	decoder := json.NewDecoder(f)
+	for {
+		var data TracesData
+		if err := decoder.Decode(&data); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+			return nil, err
+		}
+		records = append(records, data.Records()...)
+	}
+}
@@ -0,0 +1,83 @@
+package spool
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExporterRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	exporter, err := NewExporter(dir)
+	assert.Nil(t, err)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "test"))),
+	)
+	tracer := provider.Tracer("test")
+
+	start := time.Unix(1700000000, 0)
+	ctx, parent := tracer.Start(context.Background(), "parent", trace.WithTimestamp(start))
+	_, child := tracer.Start(ctx, "child",
+		trace.WithTimestamp(start.Add(time.Second)),
+		trace.WithAttributes(attribute.Int("process.exit.code", 3), attribute.StringSlice("args", []string{"a", "b"})),
+	)
+	child.SetStatus(codes.Error, "failed")
+	child.End(trace.WithTimestamp(start.Add(2 * time.Second)))
+	parent.End(trace.WithTimestamp(start.Add(3 * time.Second)))
+	assert.Nil(t, provider.Shutdown(context.Background()))
+
+	// A Chrome trace-event file exported into the spool directory is not read back as spans
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "traci-trace.json"), []byte(`[{"name":"parent","ph":"X"}]`), 0o644))
+
+	records, err := Read(dir)
+	assert.Nil(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "parent", records[0].Name)
+		assert.Equal(t, 3*time.Second, records[0].Duration())
+
+		got := records[1]
+		assert.Equal(t, "child", got.Name)
+		assert.Equal(t, records[0].SpanID, got.ParentSpanID)
+		assert.Equal(t, records[0].TraceID, got.TraceID)
+		assert.True(t, start.Add(time.Second).Equal(got.StartTime()))
+		assert.Equal(t, Status{Message: "failed", Code: StatusCodeError}, got.Status)
+
+		exitCode, _ := got.Attribute("process.exit.code")
+		assert.Equal(t, "3", exitCode)
+		args, _ := got.Attribute("args")
+		assert.Equal(t, "a,b", args)
+		service, _ := got.Attribute("service.name")
+		assert.Equal(t, "test", service)
+	}
+}
+
+func TestReadOTLPJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collector.json")
+	data := `{
+  "resourceSpans": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "ci"}}]},
+    "scopeSpans": [{"spans": [
+      {"traceId": "5b8efff798038103d269b633813fc60c", "spanId": "eee19b7ec3c1b174", "name": "job", "startTimeUnixNano": 1700000000000000000, "endTimeUnixNano": "1700000005000000000", "attributes": [{"key": "retries", "value": {"intValue": "2"}}]}
+    ]}]
+  }]
+}`
+	assert.Nil(t, os.WriteFile(path, []byte(data), 0o644))
+
+	records, err := Read(path)
+	assert.Nil(t, err)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, 5*time.Second, records[0].Duration())
+		retries, _ := records[0].Attribute("retries")
+		assert.Equal(t, "2", retries)
+	}
+}
@@ -0,0 +1,134 @@
+// Package summary renders the spans recorded during a job as a Markdown timing breakdown, suited to CI step summaries.
+package summary
+
+import (
+	"fmt"
+	"github.com/nextrevision/traci/spool"
+	"github.com/nextrevision/traci/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTop is the number of slowest steps listed by default.
+const DefaultTop = 5
+
+// Write renders a Markdown summary of records with a row for each top level span, which are the commands run in the
+// job, followed by the top slowest spans nested below them. No steps are listed when top is not positive.
+func Write(w io.Writer, records []spool.Record, top int) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "### traci job summary\n\nNo spans were recorded.")
+		return err
+	}
+
+	spanIDs := map[string]bool{}
+	start, end := records[0].StartTime(), records[0].EndTime()
+	for _, r := range records {
+		spanIDs[r.SpanID] = true
+		if r.StartTime().Before(start) {
+			start = r.StartTime()
+		}
+		if r.EndTime().After(end) {
+			end = r.EndTime()
+		}
+	}
+	jobTime := end.Sub(start)
+
+	var commands, steps []spool.Record
+	for _, r := range records {
+		if r.ParentSpanID == "" || !spanIDs[r.ParentSpanID] {
+			commands = append(commands, r)
+		} else {
+			steps = append(steps, r)
+		}
+	}
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].StartTime().Before(commands[j].StartTime())
+	})
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Duration() > steps[j].Duration()
+	})
+	if top < 0 {
+		top = 0
+	}
+	if len(steps) > top {
+		steps = steps[:top]
+	}
+
+	var b strings.Builder
+	b.WriteString("### traci job summary\n\n")
+	fmt.Fprintf(&b, "Ran %d %s in %s.\n\n", len(commands), plural(len(commands), "command", "commands"), FormatDuration(jobTime))
+
+	b.WriteString("| Command | Duration | Exit Code | Share of Job |\n")
+	b.WriteString("|---------|---------:|----------:|-------------:|\n")
+	for _, r := range commands {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", code(command(r)), FormatDuration(r.Duration()), exitCode(r), share(r.Duration(), jobTime))
+	}
+
+	if len(steps) > 0 {
+		b.WriteString("\n#### Slowest steps\n\n")
+		b.WriteString("| Step | Duration | Share of Job |\n")
+		b.WriteString("|------|---------:|-------------:|\n")
+		for _, r := range steps {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", code(r.Name), FormatDuration(r.Duration()), share(r.Duration(), jobTime))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// command returns the command line of a span recorded by traci exec, or the span name for other spans.
+func command(r spool.Record) string {
+	name, ok := r.Attribute(string(semconv.ProcessExecutableNameKey))
+	if !ok || name == "" {
+		return r.Name
+	}
+	parts := []string{name}
+	if args, ok := r.Value(string(semconv.ProcessCommandArgsKey)); ok && args.ArrayValue != nil {
+		for _, arg := range args.ArrayValue.Values {
+			parts = append(parts, arg.String())
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func exitCode(r spool.Record) string {
+	if code, ok := r.Attribute(string(tracing.ProcessExitCodeKey)); ok {
+		return code
+	}
+	if r.Status.Code == spool.StatusCodeError {
+		return "error"
+	}
+	return "-"
+}
+
+func share(d, total time.Duration) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(d)/float64(total)*100)
+}
+
+// FormatDuration rounds durations to milliseconds, or to seconds once they exceed a minute.
+func FormatDuration(d time.Duration) string {
+	if d >= time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// code formats value as an inline code span which is safe to use in a table cell.
+func code(value string) string {
+	value = strings.NewReplacer("`", "'", "\n", " ", "\r", "", "|", `\|`).Replace(value)
+	return "`" + value + "`"
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,89 @@
+package summary
+
+import (
+	"bytes"
+	"github.com/nextrevision/traci/spool"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func stringValue(s string) spool.AnyValue {
+	return spool.AnyValue{StringValue: &s}
+}
+
+func intValue(i int64) spool.AnyValue {
+	v := spool.Int64(i)
+	return spool.AnyValue{IntValue: &v}
+}
+
+func record(name, spanID, parentSpanID string, start, end time.Duration, attrs ...spool.KeyValue) spool.Record {
+	base := time.Unix(1700000000, 0)
+	return spool.Record{Span: spool.Span{
+		Name:              name,
+		SpanID:            spanID,
+		ParentSpanID:      parentSpanID,
+		StartTimeUnixNano: spool.Uint64(base.Add(start).UnixNano()),
+		EndTimeUnixNano:   spool.Uint64(base.Add(end).UnixNano()),
+		Attributes:        attrs,
+	}}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []spool.Record
+		top     int
+		want    string
+	}{
+		{
+			name:    "Case for no spans",
+			records: nil,
+			top:     DefaultTop,
+			want:    "### traci job summary\n\nNo spans were recorded.\n",
+		},
+		{
+			name: "Case for commands and steps",
+			records: []spool.Record{
+				record("ci:make", "b", "", 10*time.Second, 40*time.Second,
+					spool.KeyValue{Key: "process.executable.name", Value: stringValue("make")},
+					spool.KeyValue{Key: "process.command_args", Value: spool.AnyValue{ArrayValue: &spool.ArrayValue{Values: []spool.AnyValue{stringValue("build"), stringValue("a|b")}}}},
+					spool.KeyValue{Key: "process.exit.code", Value: intValue(2)},
+				),
+				record("ci:go test", "a", "0000000000000001", 0, 10*time.Second),
+				record("cc", "c", "b", 10*time.Second, 30*time.Second),
+				record("ld", "d", "b", 30*time.Second, 35*time.Second),
+				record("strip", "e", "b", 35*time.Second, 36500*time.Millisecond),
+			},
+			top: 2,
+			want: "### traci job summary\n\n" +
+				"Ran 2 commands in 40s.\n\n" +
+				"| Command | Duration | Exit Code | Share of Job |\n" +
+				"|---------|---------:|----------:|-------------:|\n" +
+				"| `ci:go test` | 10s | - | 25.0% |\n" +
+				"| `make build a\\|b` | 30s | 2 | 75.0% |\n" +
+				"\n#### Slowest steps\n\n" +
+				"| Step | Duration | Share of Job |\n" +
+				"|------|---------:|-------------:|\n" +
+				"| `cc` | 20s | 50.0% |\n" +
+				"| `ld` | 5s | 12.5% |\n",
+		},
+		{
+			name:    "Case for negative top",
+			records: []spool.Record{record("ci:make", "b", "", 0, 10*time.Second), record("cc", "c", "b", 0, 5*time.Second)},
+			top:     -1,
+			want: "### traci job summary\n\n" +
+				"Ran 1 command in 10s.\n\n" +
+				"| Command | Duration | Exit Code | Share of Job |\n" +
+				"|---------|---------:|----------:|-------------:|\n" +
+				"| `ci:make` | 10s | - | 100.0% |\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			assert.Nil(t, Write(&out, tt.records, tt.top))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
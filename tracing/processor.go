@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// RootAttributesProcessor is a span processor setting attributes on the root spans of a TracerProvider, the spans
+// without a parent or with a remote one, such as the parents traci derives or reads from the environment. This tags the
+// spans of each invocation of traci once, without repeating the attributes on their descendants.
+type RootAttributesProcessor struct {
+	attributes []attribute.KeyValue
+}
+
+// NewRootAttributesProcessor creates a RootAttributesProcessor setting attributes.
+func NewRootAttributesProcessor(attributes []attribute.KeyValue) *RootAttributesProcessor {
+	return &RootAttributesProcessor{attributes: attributes}
+}
+
+func (p *RootAttributesProcessor) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	if parent := s.Parent(); !parent.IsValid() || parent.IsRemote() {
+		s.SetAttributes(p.attributes...)
+	}
+}
+
+func (p *RootAttributesProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+func (p *RootAttributesProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+func (p *RootAttributesProcessor) ForceFlush(context.Context) error {
+	return nil
+}
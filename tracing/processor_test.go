@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+func TestRootAttributesProcessor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(NewRootAttributesProcessor([]attribute.KeyValue{attribute.String("ci.job", "build")})),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	tracer := provider.Tracer("test")
+
+	// The deterministic job span is the parent of root spans, but is not started by the provider
+	ctx := NewContextFromDeterministicString("pipeline", "job")
+	rootCtx, root := tracer.Start(ctx, "root")
+	_, child := tracer.Start(rootCtx, "child")
+	child.End()
+	root.End()
+	_, other := tracer.Start(context.Background(), "other")
+	other.End()
+
+	attributes := map[string][]attribute.KeyValue{}
+	for _, s := range recorder.Ended() {
+		attributes[s.Name()] = s.Attributes()
+	}
+	assert.Equal(t, map[string][]attribute.KeyValue{
+		"root":  {attribute.String("ci.job", "build")},
+		"child": nil,
+		"other": {attribute.String("ci.job", "build")},
+	}, attributes)
+}
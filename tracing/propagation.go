@@ -0,0 +1,147 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/contrib/propagators/autoprop"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"strings"
+)
+
+// EnvCarrier is a propagation.TextMapCarrier over environment variables. Propagation fields are mapped to variable
+// names by upper casing them and replacing dashes with underscores, so `traceparent` is read from TRACEPARENT,
+// `x-b3-traceid` from X_B3_TRACEID and `uber-trace-id` from UBER_TRACE_ID.
+type EnvCarrier struct {
+	environ []string
+}
+
+// NewEnvCarrier creates an EnvCarrier over environ, a list of `key=value` entries as returned by os.Environ.
+func NewEnvCarrier(environ []string) *EnvCarrier {
+	return &EnvCarrier{environ: append([]string{}, environ...)}
+}
+
+// Get returns the value of the variable for the propagation field key.
+func (c *EnvCarrier) Get(key string) string {
+	prefix := envName(key) + "="
+	for _, e := range c.environ {
+		if strings.HasPrefix(e, prefix) {
+			return e[len(prefix):]
+		}
+	}
+	return ""
+}
+
+// Set replaces the variable for the propagation field key.
+func (c *EnvCarrier) Set(key string, value string) {
+	c.Delete(key)
+	c.environ = append(c.environ, envName(key)+"="+value)
+}
+
+// Delete removes the variable for the propagation field key.
+func (c *EnvCarrier) Delete(key string) {
+	prefix := envName(key) + "="
+	environ := c.environ[:0]
+	for _, e := range c.environ {
+		if !strings.HasPrefix(e, prefix) {
+			environ = append(environ, e)
+		}
+	}
+	c.environ = environ
+}
+
+// Keys returns the propagation field names of all variables.
+func (c *EnvCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.environ))
+	for _, e := range c.environ {
+		name, _, _ := strings.Cut(e, "=")
+		keys = append(keys, strings.ToLower(strings.ReplaceAll(name, "_", "-")))
+	}
+	return keys
+}
+
+// Environ returns the variables as `key=value` entries.
+func (c *EnvCarrier) Environ() []string {
+	return c.environ
+}
+
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// NewPropagator returns the propagator configured by the OTEL_PROPAGATORS environment variable, which defaults to W3C
+// trace context and baggage. Supported values are tracecontext, baggage, b3, b3multi, jaeger, xray, ottrace and none.
+func NewPropagator() propagation.TextMapPropagator {
+	return autoprop.NewTextMapPropagator()
+}
+
+// NewContextFromEnv returns a context with the remote span context extracted from environ by the configured
+// propagators, for example from the TRACEPARENT, B3 or UBER_TRACE_ID variables, along with the trace state and
+// baggage. An error is returned if no valid span context is found, in which case the context only carries the baggage.
+func NewContextFromEnv(ctx context.Context, environ []string) (context.Context, error) {
+	newCtx := NewPropagator().Extract(ctx, NewEnvCarrier(environ))
+	if sc := trace.SpanContextFromContext(newCtx); !sc.IsValid() {
+		return newCtx, errors.New("no valid trace context found in environment")
+	}
+	return newCtx, nil
+}
+
+// NewContextFromEnvTraceParent generates a new context with a trace parent extracted from the `TRACEPARENT` environment
+// variable. If the `TRACEPARENT` environment variable is not present or invalid, the provided context is returned along
+// with an error.
+//
+// Deprecated: Use NewContextFromEnv, which also supports the other configured propagators, trace state and baggage.
+func NewContextFromEnvTraceParent(ctx context.Context) (context.Context, error) {
+	val, present := os.LookupEnv(TraceParentKey)
+	if !present {
+		return ctx, fmt.Errorf("%s variable not found in environment", TraceParentKey)
+	}
+
+	newCtx := propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": val})
+	if sc := trace.SpanContextFromContext(newCtx); !sc.IsValid() {
+		return ctx, fmt.Errorf("%s variable is invalid", TraceParentKey)
+	}
+	return newCtx, nil
+}
+
+// ParseTraceParent returns the span context of a W3C traceparent value, which is invalid if the value can't be parsed.
+func ParseTraceParent(traceParent string) trace.SpanContext {
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier{"traceparent": traceParent})
+	return trace.SpanContextFromContext(ctx)
+}
+
+// ContextWithBaggage returns ctx with members, given as `key=value` entries, added to its baggage. Existing members
+// with the same key are replaced.
+func ContextWithBaggage(ctx context.Context, members []string) (context.Context, error) {
+	bag := baggage.FromContext(ctx)
+	for _, m := range members {
+		key, value, found := strings.Cut(m, "=")
+		if !found {
+			return ctx, fmt.Errorf("invalid baggage %q, expected key=value", m)
+		}
+		member, err := baggage.NewMemberRaw(strings.TrimSpace(key), strings.TrimSpace(value))
+		if err != nil {
+			return ctx, fmt.Errorf("invalid baggage %q: %w", m, err)
+		}
+		if bag, err = bag.SetMember(member); err != nil {
+			return ctx, fmt.Errorf("invalid baggage %q: %w", m, err)
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
+
+// InjectEnv returns environ with the propagation variables replaced by those of the span context in ctx, written by
+// the configured propagators. TRACEPARENT is always written alongside them, so commands only supporting W3C trace
+// context never inherit a stale one.
+func InjectEnv(ctx context.Context, environ []string) []string {
+	propagator := propagation.NewCompositeTextMapPropagator(NewPropagator(), propagation.TraceContext{})
+	carrier := NewEnvCarrier(environ)
+	for _, field := range propagator.Fields() {
+		carrier.Delete(field)
+	}
+	propagator.Inject(ctx, carrier)
+	return carrier.Environ()
+}
@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"testing"
+)
+
+func TestEnvCarrier(t *testing.T) {
+	carrier := NewEnvCarrier([]string{"PATH=/bin", "X_B3_TRACEID=abc", "EMPTY="})
+
+	assert.Equal(t, "abc", carrier.Get("x-b3-traceid"))
+	assert.Equal(t, "", carrier.Get("b3"))
+	assert.Equal(t, []string{"path", "x-b3-traceid", "empty"}, carrier.Keys())
+
+	carrier.Set("uber-trace-id", "def")
+	carrier.Set("x-b3-traceid", "ghi")
+	assert.Equal(t, []string{"PATH=/bin", "EMPTY=", "UBER_TRACE_ID=def", "X_B3_TRACEID=ghi"}, carrier.Environ())
+
+	carrier.Delete("uber-trace-id")
+	assert.Equal(t, []string{"PATH=/bin", "EMPTY=", "X_B3_TRACEID=ghi"}, carrier.Environ())
+}
+
+func TestPropagationFormats(t *testing.T) {
+	traceID := mustTraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID := mustSpanIDFromHex("00f067aa0ba902b7")
+
+	testCases := []struct {
+		name        string
+		propagators string
+		environ     []string
+		wantEnviron []string
+	}{
+		{
+			name:        "Case for default tracecontext",
+			propagators: "",
+			environ:     []string{"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			wantEnviron: []string{"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		},
+		{
+			name:        "Case for tracecontext with trace state",
+			propagators: "tracecontext",
+			environ: []string{
+				"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"TRACESTATE=vendor=opaque",
+			},
+			wantEnviron: []string{
+				"TRACESTATE=vendor=opaque",
+				"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+		},
+		{
+			name:        "Case for b3 single header",
+			propagators: "b3",
+			environ:     []string{"B3=4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"},
+			wantEnviron: []string{
+				"B3=4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+				"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+		},
+		{
+			name:        "Case for b3 with stale TRACEPARENT",
+			propagators: "b3",
+			environ: []string{
+				"TRACEPARENT=00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+				"B3=4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+			},
+			wantEnviron: []string{
+				"B3=4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+				"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+		},
+		{
+			name:        "Case for b3 multiple headers",
+			propagators: "b3multi",
+			environ: []string{
+				"X_B3_TRACEID=4bf92f3577b34da6a3ce929d0e0e4736",
+				"X_B3_SPANID=00f067aa0ba902b7",
+				"X_B3_SAMPLED=1",
+			},
+			wantEnviron: []string{
+				"X_B3_TRACEID=4bf92f3577b34da6a3ce929d0e0e4736",
+				"X_B3_SPANID=00f067aa0ba902b7",
+				"X_B3_SAMPLED=1",
+				"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+		},
+		{
+			name:        "Case for jaeger and tracecontext",
+			propagators: "jaeger,tracecontext",
+			environ:     []string{"UBER_TRACE_ID=4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:1"},
+			wantEnviron: []string{
+				"UBER_TRACE_ID=4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:1",
+				"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("OTEL_PROPAGATORS", tc.propagators)
+
+			ctx, err := NewContextFromEnv(context.Background(), tc.environ)
+			assert.Nil(t, err)
+			spanCtx := trace.SpanContextFromContext(ctx)
+			assert.Equal(t, traceID, spanCtx.TraceID())
+			assert.Equal(t, spanID, spanCtx.SpanID())
+
+			// Injecting the extracted context replaces the stale variables with the same values
+			environ := InjectEnv(ctx, append([]string{"PATH=/bin"}, tc.environ...))
+			assert.Equal(t, append([]string{"PATH=/bin"}, tc.wantEnviron...), environ)
+		})
+	}
+}
+
+func TestContextWithBaggage(t *testing.T) {
+	testCases := []struct {
+		name        string
+		environ     []string
+		members     []string
+		wantBaggage string
+		wantErr     bool
+	}{
+		{
+			name:        "Case for baggage from environment",
+			environ:     []string{"BAGGAGE=team=ci"},
+			members:     nil,
+			wantBaggage: "team=ci",
+		},
+		{
+			name:        "Case for adding and replacing members",
+			environ:     []string{"BAGGAGE=team=ci,env=dev"},
+			members:     []string{"env=prod", "pipeline=release build"},
+			wantBaggage: "team=ci,env=prod,pipeline=release%20build",
+		},
+		{
+			name:        "Case for invalid member",
+			environ:     []string{"BAGGAGE=team=ci"},
+			members:     []string{"novalue"},
+			wantBaggage: "team=ci",
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, err := NewContextFromEnv(context.Background(), tc.environ)
+			assert.Error(t, err)
+
+			ctx, err = ContextWithBaggage(ctx, tc.members)
+			assert.Equal(t, tc.wantErr, err != nil)
+
+			environ := InjectEnv(ctx, nil)
+			assert.Len(t, environ, 1)
+			assert.ElementsMatch(t, strings.Split(tc.wantBaggage, ","), strings.Split(strings.TrimPrefix(environ[0], "BAGGAGE="), ","))
+		})
+	}
+}
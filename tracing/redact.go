@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "REDACTED"
+
+// sensitiveNamePattern matches names containing a sensitive looking word, delimited by dashes, underscores, dots or the
+// start and end of the name, so `--auth-token` and `DB_PASSWORD` match but `--author` doesn't.
+var sensitiveNamePattern = regexp.MustCompile(`(?i)(^|[-_.])(passw(or)?d|secrets?|tokens?|api[-_]?keys?|access[-_]?keys?|private[-_]?keys?|credentials?|auth)($|[-_.])`)
+
+// camelCaseBoundary matches the boundary between the words of camel case names such as `apiToken`.
+var camelCaseBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// RedactArgs returns a copy of args with the values of likely secrets replaced.
+// Values are redacted when they are assigned to a sensitive looking name (`--token=foo`, `PASSWORD=foo`), when they
+// follow a sensitive looking flag (`--password foo`) or when they are credentials embedded in a URL.
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		if redactNext {
+			redacted[i] = redactedValue
+			redactNext = false
+			continue
+		}
+
+		if name, _, found := strings.Cut(arg, "="); found && isSensitiveName(name) {
+			redacted[i] = name + "=" + redactedValue
+			continue
+		}
+
+		// Negated boolean flags such as `--no-auth` don't take a value
+		if strings.HasPrefix(arg, "-") && isSensitiveName(arg) && !strings.HasPrefix(strings.TrimLeft(arg, "-"), "no-") {
+			redacted[i] = arg
+			redactNext = true
+			continue
+		}
+
+		redacted[i] = redactURLCredentials(arg)
+	}
+	return redacted
+}
+
+// isSensitiveName reports whether a flag or variable name, without its leading dashes, contains a sensitive looking word.
+func isSensitiveName(name string) bool {
+	name = camelCaseBoundary.ReplaceAllString(strings.TrimLeft(name, "-"), "${1}_${2}")
+	return sensitiveNamePattern.MatchString(name)
+}
+
+// redactURLCredentials replaces the password of a URL containing user info, returning all other values unchanged.
+func redactURLCredentials(value string) string {
+	if !strings.Contains(value, "://") || !strings.Contains(value, "@") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), redactedValue)
+	} else {
+		u.User = url.User(redactedValue)
+	}
+	return u.String()
+}
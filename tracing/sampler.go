@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"fmt"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	samplerEnvKey    = "OTEL_TRACES_SAMPLER"
+	samplerArgEnvKey = "OTEL_TRACES_SAMPLER_ARG"
+)
+
+// envSampler returns the sampler for root spans and whether it is wrapped in a parent based sampler, parsed from the
+// environment once so invalid configuration is only reported once per invocation.
+var envSampler = sync.OnceValues(samplerFromEnv)
+
+// NewSampler returns the sampler configured by the OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG environment
+// variables, defaulting to parentbased_always_on. Supported samplers are always_on, always_off, traceidratio and their
+// parentbased_ variants, where the argument of traceidratio is the sampling probability between 0 and 1.
+func NewSampler() sdktrace.Sampler {
+	root, parentBased := envSampler()
+	if parentBased {
+		return sdktrace.ParentBased(root)
+	}
+	return root
+}
+
+// sampledFlags returns the trace flags of the sampling decision of the root sampler for traceID. Trace ID ratio
+// sampling only depends on the trace ID, so every invocation within a deterministic trace makes the same decision.
+func sampledFlags(traceID trace.TraceID) trace.TraceFlags {
+	root, _ := envSampler()
+	result := root.ShouldSample(sdktrace.SamplingParameters{TraceID: traceID})
+	if result.Decision == sdktrace.RecordAndSample {
+		return trace.FlagsSampled
+	}
+	return 0
+}
+
+// samplerFromEnv returns the sampler for root spans and whether it is wrapped in a parent based sampler. Invalid
+// configuration falls back to the parentbased_always_on default.
+func samplerFromEnv() (sdktrace.Sampler, bool) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv(samplerEnvKey)))
+	parentBased := name == "" || strings.HasPrefix(name, "parentbased_")
+
+	switch strings.TrimPrefix(name, "parentbased_") {
+	case "", "always_on":
+		return sdktrace.AlwaysSample(), parentBased
+	case "always_off":
+		return sdktrace.NeverSample(), parentBased
+	case "traceidratio":
+		ratio, err := samplerRatio()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR invalid %s: %v\n", samplerArgEnvKey, err)
+			return sdktrace.AlwaysSample(), true
+		}
+		return sdktrace.TraceIDRatioBased(ratio), parentBased
+	}
+
+	fmt.Fprintf(os.Stderr, "ERROR unsupported %s %q\n", samplerEnvKey, name)
+	return sdktrace.AlwaysSample(), true
+}
+
+// samplerRatio returns the sampling probability in OTEL_TRACES_SAMPLER_ARG, which defaults to 1.
+func samplerRatio() (float64, error) {
+	arg := strings.TrimSpace(os.Getenv(samplerArgEnvKey))
+	if arg == "" {
+		return 1, nil
+	}
+	ratio, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, err
+	}
+	if ratio < 0 || ratio > 1 {
+		return 0, fmt.Errorf("ratio %v is not between 0 and 1", ratio)
+	}
+	return ratio, nil
+}
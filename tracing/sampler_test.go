@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestNewSampler(t *testing.T) {
+	testCases := []struct {
+		name            string
+		sampler         string
+		samplerArg      string
+		wantDescription string
+		wantSampled     bool
+	}{
+		{
+			name:            "Case for default",
+			wantDescription: "ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}",
+			wantSampled:     true,
+		},
+		{
+			name:            "Case for always off",
+			sampler:         "always_off",
+			wantDescription: "AlwaysOffSampler",
+			wantSampled:     false,
+		},
+		{
+			name:            "Case for trace ID ratio of zero",
+			sampler:         "parentbased_traceidratio",
+			samplerArg:      "0",
+			wantDescription: "ParentBased{root:TraceIDRatioBased{0},remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}",
+			wantSampled:     false,
+		},
+		{
+			name:            "Case for trace ID ratio without argument",
+			sampler:         "traceidratio",
+			wantDescription: "AlwaysOnSampler",
+			wantSampled:     true,
+		},
+		{
+			name:            "Case for invalid ratio",
+			sampler:         "traceidratio",
+			samplerArg:      "2",
+			wantDescription: "ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}",
+			wantSampled:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(samplerEnvKey, tc.sampler)
+			t.Setenv(samplerArgEnvKey, tc.samplerArg)
+			resetEnvSampler(t)
+
+			assert.Equal(t, tc.wantDescription, NewSampler().Description())
+
+			// The deterministic parent carries the root decision, which the parent based sampler then follows
+			ctx := NewContextFromDeterministicString("pipeline", "")
+			assert.Equal(t, tc.wantSampled, trace.SpanContextFromContext(ctx).IsSampled())
+
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(NewSampler()))
+			_, span := provider.Tracer("test").Start(ctx, "test")
+			assert.Equal(t, tc.wantSampled, span.SpanContext().IsSampled())
+		})
+	}
+}
+
+func TestTraceIDRatioIsDeterministic(t *testing.T) {
+	t.Setenv(samplerEnvKey, "parentbased_traceidratio")
+	t.Setenv(samplerArgEnvKey, "0.5")
+	resetEnvSampler(t)
+
+	sampled := map[bool]int{}
+	for _, pipeline := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"} {
+		first := trace.SpanContextFromContext(NewContextFromDeterministicString(pipeline, "")).IsSampled()
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, first, trace.SpanContextFromContext(NewContextFromDeterministicString(pipeline, "")).IsSampled())
+		}
+		sampled[first]++
+	}
+	assert.NotZero(t, sampled[true])
+	assert.NotZero(t, sampled[false])
+}
+
+func TestInvalidSamplerReportedOnce(t *testing.T) {
+	t.Setenv(samplerEnvKey, "probabilistic")
+	resetEnvSampler(t)
+
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	assert.Nil(t, err)
+	defer stderr.Close()
+	origStderr := os.Stderr
+	os.Stderr = stderr
+	defer func() { os.Stderr = origStderr }()
+
+	NewSampler()
+	NewContextFromDeterministicString("pipeline", "job")
+	NewContextFromDeterministicString("pipeline", "")
+
+	data, err := os.ReadFile(stderr.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, "ERROR unsupported OTEL_TRACES_SAMPLER \"probabilistic\"\n", string(data))
+}
+
+// resetEnvSampler parses the sampler configuration from the environment again, and once more after the test.
+func resetEnvSampler(t *testing.T) {
+	envSampler = sync.OnceValues(samplerFromEnv)
+	t.Cleanup(func() {
+		envSampler = sync.OnceValues(samplerFromEnv)
+	})
+}
@@ -7,12 +7,12 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"github.com/nextrevision/traci/spool"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
@@ -25,13 +25,34 @@ import (
 
 const TraceParentKey = "TRACEPARENT"
 
-func NewTraceProvider(ctx context.Context, serviceName string, resourceAttributes []attribute.KeyValue) *sdktrace.TracerProvider {
+// ProcessExitCodeKey records the exit code of a wrapped command, which is not part of the semantic conventions version
+// used by traci yet.
+const ProcessExitCodeKey = attribute.Key("process.exit.code")
+
+// NewTraceProvider creates a TracerProvider which exports each span synchronously as it ends. Additional options, such
+// as further span processors, are applied to the provider.
+func NewTraceProvider(ctx context.Context, resources *resource.Resource, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR could not create span exporter: %v\n", err)
+	}
+	return newTraceProvider(resources, append(opts, sdktrace.WithSyncer(exporter))...)
+}
+
+// NewBatchTraceProvider creates a TracerProvider which exports spans in batches, suited to recording many spans at once.
+func NewBatchTraceProvider(ctx context.Context, resources *resource.Resource, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
 	exporter, err := newExporter(ctx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR could not create span exporter: %v\n", err)
 	}
+	return newTraceProvider(resources, append(opts, sdktrace.WithBatcher(exporter))...)
+}
 
-	resources, err := resource.New(ctx,
+// NewResource creates the resource of spans with the service name, resourceAttributes and the attributes detected from
+// the environment, container, host and traci's process. The PID of traci is only included if perProcess is set, as it
+// makes the resource of every invocation distinct.
+func NewResource(ctx context.Context, serviceName string, resourceAttributes []attribute.KeyValue, perProcess bool) *resource.Resource {
+	opts := []resource.Option{
 		resource.WithAttributes(resourceAttributes...),
 		resource.WithAttributes(semconv.ServiceName(serviceName)),
 		resource.WithContainer(),
@@ -39,18 +60,26 @@ func NewTraceProvider(ctx context.Context, serviceName string, resourceAttribute
 		resource.WithHost(),
 		resource.WithOS(),
 		resource.WithProcessOwner(),
-		resource.WithProcessPID(),
 		resource.WithProcessRuntimeName(),
 		resource.WithProcessRuntimeVersion(),
 		resource.WithProcessRuntimeDescription(),
-	)
+	}
+	if perProcess {
+		opts = append(opts, resource.WithProcessPID())
+	}
+
+	// Detector errors still return a partial resource, which is preferable to failing the command
+	resources, _ := resource.New(ctx, opts...)
+	return resources
+}
 
+func newTraceProvider(resources *resource.Resource, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
 	// Create provider using the exporter
-	return sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithSyncer(exporter),
+	opts = append(opts,
+		sdktrace.WithSampler(NewSampler()),
 		sdktrace.WithResource(resources),
 	)
+	return sdktrace.NewTracerProvider(opts...)
 }
 
 func NewTracer(name string, provider trace.TracerProvider) trace.Tracer {
@@ -70,14 +99,32 @@ func newHttpExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
 	return otlptracehttp.New(ctx)
 }
 
+// newChromeExporter appends spans in Chrome trace-event format to the file in TRACI_CHROME_TRACE_FILE, or traci-trace.json.
+func newChromeExporter() (sdktrace.SpanExporter, error) {
+	path := os.Getenv("TRACI_CHROME_TRACE_FILE")
+	if path == "" {
+		path = "traci-trace.json"
+	}
+	return spool.NewChromeExporter(path), nil
+}
+
 // newExporter generates a new instance of sdktrace.SpanExporter based on the provided context.
-// It reads the OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_PROTOCOL environment variables to determine the exporter type.
+// If TRACI_EXPORTER is chrome, it returns a new instance of Chrome trace-event file exporter created by newChromeExporter.
+// Otherwise it reads the OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_PROTOCOL environment variables to determine the exporter type.
 // If OTEL_EXPORTER_OTLP_PROTOCOL is not set, it checks the endpoint to infer the protocol (grpc or http/json).
 // If the protocol is grpc, it returns a new instance of grpc exporter created by newGrpcExporter.
 // If the protocol is http, it returns a new instance of HTTP exporter created by newHttpExporter.
 // If the protocol is console, it returns a new instance of console exporter created by newConsoleExporter.
 // The context is passed to the selected exporter function for proper initialization and configuration.
 func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch exporter := os.Getenv("TRACI_EXPORTER"); exporter {
+	case "", "otlp":
+	case "chrome":
+		return newChromeExporter()
+	default:
+		return &tracetest.NoopExporter{}, fmt.Errorf("unsupported TRACI_EXPORTER %s, expected otlp or chrome", exporter)
+	}
+
 	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
 	proto := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
 
@@ -101,62 +148,69 @@ func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
 	return &tracetest.NoopExporter{}, errors.New("could not determine OTLP protocol; set with env var OTEL_EXPORTER_OTLP_PROTOCOL")
 }
 
-// GenTraceParentString formats the TraceID and SpanID from the provided SpanContext and returns a W3C TraceParent string
+// GenTraceParentString formats the TraceID, SpanID and TraceFlags from the provided SpanContext and returns a W3C
+// TraceParent string
 func GenTraceParentString(spanContext trace.SpanContext) string {
-	return fmt.Sprintf("00-%s-%s-01", spanContext.TraceID(), spanContext.SpanID())
-}
-
-// NewContextFromEnvTraceParent generates a new context with a trace parent extracted from the `TRACEPARENT` environment variable.
-// If the `TRACEPARENT` environment variable is not present, the function returns the provided context.
-//
-// Example usage:
-//
-//	 os.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
-//		ctx := NewContextFromEnvTraceParent(context.Background())
-func NewContextFromEnvTraceParent(ctx context.Context) (context.Context, error) {
-	val, present := os.LookupEnv(TraceParentKey)
-	if !present {
-		return ctx, fmt.Errorf("%s variable not found in environment", TraceParentKey)
-	}
-
-	carrier := propagation.MapCarrier{}
-	carrier.Set("traceparent", val)
-
-	tc := propagation.TraceContext{}
-	newCtx := tc.Extract(ctx, carrier)
-	if sc := trace.SpanContextFromContext(newCtx); !sc.IsValid() {
-		return ctx, fmt.Errorf("%s variable is invalid", TraceParentKey)
-	}
-
-	return newCtx, nil
+	return fmt.Sprintf("00-%s-%s-%s", spanContext.TraceID(), spanContext.SpanID(), spanContext.TraceFlags())
 }
 
 // NewContextFromDeterministicString generates a new context with a deterministic trace ID and span ID from the provided strings.
-// The returned context will include the trace ID, span ID, and trace flags set.
+// The returned context will include the trace ID, span ID, and trace flags set. The span context is remote, as no span
+// of this process has these IDs. A random span ID is used if the span ID string is empty. The sampled flag is the
+// decision of the configured root sampler, so a parent based sampler makes the same decision for every span of the
+// trace.
 //
 // Example usage:
 //
 //	ctx := NewContextFromDeterministicString("foo", "bar")
 //	tracer := otel.Tracer("test-tracer")
 //	ctxSpan, span := tracer.Start(ctx, "test-span")
-func NewContextFromDeterministicString(traceIDString string) context.Context {
+func NewContextFromDeterministicString(traceIDString string, spanIDString string) context.Context {
+	return trace.ContextWithRemoteSpanContext(context.Background(), NewDeterministicSpanContext(traceIDString, spanIDString))
+}
+
+// NewDeterministicSpanContext returns the span context with the trace ID derived from traceIDString and the span ID
+// derived from spanIDString, or a random span ID if spanIDString is empty.
+func NewDeterministicSpanContext(traceIDString string, spanIDString string) trace.SpanContext {
 	traceID, err := genTraceIDFromString(traceIDString)
 	if err != nil {
 		log.Fatalf("could not generate trace ID from string %s\n", traceIDString)
 	}
 
-	bytes := make([]byte, 8)
-	rand.Read(bytes)
-	spanID, err := genSpanIDFromString(hex.EncodeToString(bytes))
+	if spanIDString == "" {
+		bytes := make([]byte, 8)
+		rand.Read(bytes)
+		spanIDString = hex.EncodeToString(bytes)
+	}
+	spanID, err := genSpanIDFromString(spanIDString)
 	if err != nil {
-		log.Fatalf("could not generate trace ID from string %s\n", traceIDString)
+		log.Fatalf("could not generate span ID from string %s\n", spanIDString)
 	}
 
-	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+	return trace.NewSpanContext(trace.SpanContextConfig{
 		TraceID:    traceID,
 		SpanID:     spanID,
-		TraceFlags: 0x0,
-	}))
+		TraceFlags: sampledFlags(traceID),
+	})
+}
+
+// WithSpanContextIDs returns a TracerProvider option giving its spans the trace ID and span ID of spanContext, so a span
+// can be emitted for a span context which was already handed out as the parent of other spans, such as the job span.
+func WithSpanContextIDs(spanContext trace.SpanContext) sdktrace.TracerProviderOption {
+	return sdktrace.WithIDGenerator(spanContextIDGenerator{spanContext: spanContext})
+}
+
+// spanContextIDGenerator is an sdktrace.IDGenerator which always returns the IDs of spanContext.
+type spanContextIDGenerator struct {
+	spanContext trace.SpanContext
+}
+
+func (g spanContextIDGenerator) NewIDs(_ context.Context) (trace.TraceID, trace.SpanID) {
+	return g.spanContext.TraceID(), g.spanContext.SpanID()
+}
+
+func (g spanContextIDGenerator) NewSpanID(_ context.Context, _ trace.TraceID) trace.SpanID {
+	return g.spanContext.SpanID()
 }
 
 // genTraceIDFromString generates an idempotent trace.TraceID from an arbitrary string.
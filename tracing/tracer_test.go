@@ -58,6 +58,24 @@ func TestNewExporter(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Case for chrome exporter",
+			setVars: func() {
+				os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+				os.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "")
+				os.Setenv("TRACI_EXPORTER", "chrome")
+			},
+			wantErr: false,
+		},
+		{
+			name: "Case for unsupported exporter",
+			setVars: func() {
+				os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+				os.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+				os.Setenv("TRACI_EXPORTER", "zipkin")
+			},
+			wantErr: true,
+		},
 		{
 			name: "Default noop exporter with err",
 			setVars: func() {
@@ -70,6 +88,7 @@ func TestNewExporter(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TRACI_EXPORTER", "")
 			tc.setVars()
 			got, err := newExporter(ctx)
 			if (err != nil) != tc.wantErr {
@@ -95,17 +114,27 @@ func TestGenTraceParentString(t *testing.T) {
 
 		{
 			name: "Valid input",
+			spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    mustTraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736"),
+				SpanID:     mustSpanIDFromHex("00f067aa0ba902b7"),
+				TraceFlags: trace.FlagsSampled,
+			}),
+			want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+
+		{
+			name: "Not sampled",
 			spanContext: trace.NewSpanContext(trace.SpanContextConfig{
 				TraceID: mustTraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736"),
 				SpanID:  mustSpanIDFromHex("00f067aa0ba902b7"),
 			}),
-			want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
 		},
 
 		{
 			name:        "Empty input",
 			spanContext: trace.SpanContext{},
-			want:        "00-00000000000000000000000000000000-0000000000000000-01",
+			want:        "00-00000000000000000000000000000000-0000000000000000-00",
 		},
 	}
 
@@ -118,6 +147,65 @@ func TestGenTraceParentString(t *testing.T) {
 
 }
 
+func TestNewContextFromEnv(t *testing.T) {
+	// preparing a table driven test
+	testCases := []struct {
+		name        string // name of the test
+		envKey      string
+		envValue    string // 'traceparent' environment variable's value
+		wantTraceID trace.TraceID
+		wantSpanID  trace.SpanID
+		wantErr     bool // flag indicating whether error is expected or not
+	}{
+		{
+			name:        "No env var present",
+			envKey:      "foo",
+			envValue:    "",
+			wantTraceID: trace.TraceID{},
+			wantSpanID:  trace.SpanID{},
+			wantErr:     true,
+		},
+		{
+			name:        "Env var present",
+			envKey:      TraceParentKey,
+			envValue:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTraceID: mustTraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736"),
+			wantSpanID:  mustSpanIDFromHex("00f067aa0ba902b7"),
+			wantErr:     false,
+		},
+		{
+			name:        "Env var invalid",
+			envKey:      TraceParentKey,
+			envValue:    "foobarbaz",
+			wantTraceID: trace.TraceID{},
+			wantSpanID:  trace.SpanID{},
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Call the function under test
+			ctx, err := NewContextFromEnv(context.Background(), []string{tc.envKey + "=" + tc.envValue})
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else if err != nil {
+				t.Errorf("unexepcted error returned %s", err.Error())
+			} else {
+				spanCtx := trace.SpanContextFromContext(ctx)
+
+				// If we do not expect an error, then the original & returned context should not be equal
+				if !spanCtx.IsValid() || spanCtx.TraceID() != tc.wantTraceID || spanCtx.SpanID() != tc.wantSpanID {
+					t.Errorf("unexpected span context")
+				}
+			}
+		})
+
+		// Cleanup after each test
+		os.Unsetenv(tc.envKey)
+	}
+}
+
 func TestNewContextFromEnvTraceParent(t *testing.T) {
 	// preparing a table driven test
 	testCases := []struct {
@@ -206,7 +294,7 @@ func TestNewContextFromDeterministicString(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			ctx := NewContextFromDeterministicString(tc.traceIDString)
+			ctx := NewContextFromDeterministicString(tc.traceIDString, tc.spanIDString)
 			span := trace.SpanContextFromContext(ctx)
 
 			if tc.wantErr {
@@ -217,6 +305,9 @@ func TestNewContextFromDeterministicString(t *testing.T) {
 				if !span.IsValid() || span.TraceID() != tc.wantTraceID {
 					t.Errorf("unexpected span context")
 				}
+				if tc.spanIDString != "" && span.SpanID() != mustSpanIDFromHex(tc.wantTraceID.String()[:16]) {
+					t.Errorf("unexpected span ID %s", span.SpanID())
+				}
 			}
 		})
 	}